@@ -0,0 +1,315 @@
+//Package client is a typed client of the v1 cart API
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+)
+
+//Client calls the v1 routes of the cart API, errors answered by the API are *Error
+type Client interface {
+	//Health reports every dependency, it is answered when the service is not ready too: check its Status
+	Health(ctx context.Context) (viewmodels.HealthResponse, error)
+	Live(ctx context.Context) (viewmodels.HealthResponse, error)
+	//Ready is answered when the service is not ready too: check its Status
+	Ready(ctx context.Context) (viewmodels.HealthResponse, error)
+	HealthHistory(ctx context.Context) (viewmodels.HealthHistoryResponse, error)
+
+	CreateCart(ctx context.Context) (viewmodels.Cart, error)
+	GetCart(ctx context.Context, cartID string) (viewmodels.Cart, error)
+	DeleteCart(ctx context.Context, cartID string) error
+	AddItem(ctx context.Context, cartID, itemID string, quantity int) (viewmodels.Cart, error)
+	UpdateQuantity(ctx context.Context, cartID, itemID string, quantity int) (viewmodels.Cart, error)
+	RemoveItem(ctx context.Context, cartID, itemID string) (viewmodels.Cart, error)
+	RemoveAllItems(ctx context.Context, cartID string) (viewmodels.Cart, error)
+
+	GetAvailableItems(ctx context.Context) ([]viewmodels.Item, error)
+	GetItem(ctx context.Context, itemID string) (viewmodels.Item, error)
+
+	//SharedCart joins the WebSocket where viewers edit the cart together, viewer is a random name when empty
+	SharedCart(ctx context.Context, cartID, viewer string) (SharedCart, error)
+}
+
+//HTTPClient sends the requests, *http.Client is one
+type HTTPClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type client struct {
+	baseURL    string
+	httpClient HTTPClient
+	tenant     string
+	language   string
+	retries    int
+	backoff    time.Duration
+}
+
+//Option customizes the client
+type Option func(*client)
+
+//WithHTTPClient sends the requests through httpClient. http.DefaultClient by default.
+func WithHTTPClient(httpClient HTTPClient) Option {
+	return func(c *client) {
+		c.httpClient = httpClient
+	}
+}
+
+//WithTenant sends every request on behalf of a tenant. The default tenant when not set.
+func WithTenant(t string) Option {
+	return func(c *client) {
+		c.tenant = t
+	}
+}
+
+//WithLanguage asks for error descriptions in a language, as an Accept-Language header
+func WithLanguage(language string) Option {
+	return func(c *client) {
+		c.language = language
+	}
+}
+
+//WithRetries is how many times a request is retried when it doesn't reach the API or the API answers
+//a retryable error, waiting backoff before the first retry and twice as long before every other one.
+//Only GET, PUT and DELETE are retried, they are idempotent. 2 retries from 100ms by default, 0 disables them.
+//An attempt that failed may still have reached the API, so a retried DELETE answered with a 404 counts as done:
+//DeleteCart succeeds, RemoveItem succeeds with the cart as it is now. RemoveAllItems still fails, its 404 is a missing cart.
+func WithRetries(retries int, backoff time.Duration) Option {
+	return func(c *client) {
+		c.retries = retries
+		c.backoff = backoff
+	}
+}
+
+//NewClient gives a Client of the API served at baseURL, like http://cart-service:8080
+func NewClient(baseURL string, opts ...Option) Client {
+	c := &client{
+		baseURL:    strings.TrimSuffix(baseURL, "/") + "/v1",
+		httpClient: http.DefaultClient,
+		retries:    2,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+func (c *client) Health(ctx context.Context) (viewmodels.HealthResponse, error) {
+	hr := viewmodels.HealthResponse{}
+	err := c.do(ctx, http.MethodGet, "/health", nil, &hr, http.StatusServiceUnavailable)
+	return hr, err
+}
+
+func (c *client) Live(ctx context.Context) (viewmodels.HealthResponse, error) {
+	hr := viewmodels.HealthResponse{}
+	err := c.do(ctx, http.MethodGet, "/health/live", nil, &hr)
+	return hr, err
+}
+
+func (c *client) Ready(ctx context.Context) (viewmodels.HealthResponse, error) {
+	hr := viewmodels.HealthResponse{}
+	err := c.do(ctx, http.MethodGet, "/health/ready", nil, &hr, http.StatusServiceUnavailable)
+	return hr, err
+}
+
+func (c *client) HealthHistory(ctx context.Context) (viewmodels.HealthHistoryResponse, error) {
+	hr := viewmodels.HealthHistoryResponse{}
+	err := c.do(ctx, http.MethodGet, "/health/history", nil, &hr)
+	return hr, err
+}
+
+func (c *client) CreateCart(ctx context.Context) (viewmodels.Cart, error) {
+	return c.cart(ctx, http.MethodPost, "/cart", nil)
+}
+
+func (c *client) GetCart(ctx context.Context, cartID string) (viewmodels.Cart, error) {
+	return c.cart(ctx, http.MethodGet, cartPath(cartID), nil)
+}
+
+func (c *client) DeleteCart(ctx context.Context, cartID string) error {
+	err := c.do(ctx, http.MethodDelete, cartPath(cartID), nil, nil)
+	if deletedOnRetry(err) {
+		return nil
+	}
+	return err
+}
+
+func (c *client) AddItem(ctx context.Context, cartID, itemID string, quantity int) (viewmodels.Cart, error) {
+	return c.cart(ctx, http.MethodPost, cartPath(cartID, "item"), viewmodels.AddItemToCartRequest{ID: itemID, Quantity: quantity})
+}
+
+func (c *client) UpdateQuantity(ctx context.Context, cartID, itemID string, quantity int) (viewmodels.Cart, error) {
+	return c.cart(ctx, http.MethodPut, cartPath(cartID, "item", itemID), viewmodels.ModifyItemQuantityRequest{Quantity: quantity})
+}
+
+func (c *client) RemoveItem(ctx context.Context, cartID, itemID string) (viewmodels.Cart, error) {
+	cart, err := c.cart(ctx, http.MethodDelete, cartPath(cartID, "item", itemID), nil)
+	if deletedOnRetry(err) && errors.Is(err, ErrItemNotFound) {
+		return c.GetCart(ctx, cartID)
+	}
+	return cart, err
+}
+
+func (c *client) RemoveAllItems(ctx context.Context, cartID string) (viewmodels.Cart, error) {
+	return c.cart(ctx, http.MethodDelete, cartPath(cartID, "item", "all"), nil)
+}
+
+func (c *client) GetAvailableItems(ctx context.Context) ([]viewmodels.Item, error) {
+	items := []viewmodels.Item{}
+	err := c.do(ctx, http.MethodGet, "/items/available", nil, &items)
+	return items, err
+}
+
+func (c *client) GetItem(ctx context.Context, itemID string) (viewmodels.Item, error) {
+	item := viewmodels.Item{}
+	err := c.do(ctx, http.MethodGet, "/items/"+url.PathEscape(itemID), nil, &item)
+	return item, err
+}
+
+//cart calls a route answering a CartResponse
+func (c *client) cart(ctx context.Context, method, path string, body interface{}) (viewmodels.Cart, error) {
+	res := viewmodels.CartResponse{}
+	err := c.do(ctx, method, path, body, &res)
+	return res.Cart, err
+}
+
+//cartPath is the path of a cart, or of something in it
+func cartPath(cartID string, elems ...string) string {
+	path := "/cart/" + url.PathEscape(cartID)
+	for _, elem := range elems {
+		path += "/" + url.PathEscape(elem)
+	}
+	return path
+}
+
+//envelope is a BaseResponse to decode
+type envelope struct {
+	Meta  viewmodels.Meta   `json:"meta"`
+	Data  json.RawMessage   `json:"data"`
+	Error *viewmodels.Error `json:"error"`
+}
+
+//do sends the request, retrying it when it can, and decodes the data of the response into out.
+//Responses with a 2xx status or one of dataStatuses carry data, the others are errors.
+func (c *client) do(ctx context.Context, method, path string, body interface{}, out interface{}, dataStatuses ...int) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	retries := 0
+	if method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete {
+		retries = c.retries
+	}
+	backoff := c.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := c.send(ctx, method, path, payload, out, dataStatuses)
+		apiErr := &Error{}
+		if attempt > 0 && method == http.MethodDelete && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+			return retriedNotFound{err: apiErr}
+		}
+		if err == nil || !retry || attempt >= retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+//retriedNotFound is the 404 answered to a retried DELETE, the attempt that failed may have deleted it already
+type retriedNotFound struct {
+	err *Error
+}
+
+func (e retriedNotFound) Error() string {
+	return e.err.Error()
+}
+
+func (e retriedNotFound) Unwrap() error {
+	return e.err
+}
+
+//deletedOnRetry tells whether err is the 404 of a retried DELETE
+func deletedOnRetry(err error) bool {
+	return errors.As(err, &retriedNotFound{})
+}
+
+//send sends the request once, retry tells whether it failed in a way worth trying again:
+//the request did not reach the API, or the API answered a retryable error
+func (c *client) send(ctx context.Context, method, path string, payload []byte, out interface{}, dataStatuses []int) (retry bool, err error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.tenant != "" {
+		req.Header.Set(tenant.HeaderKey, c.tenant)
+	}
+	if c.language != "" {
+		req.Header.Set("Accept-Language", c.language)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.HeaderKey, id)
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		//the context being done is not worth retrying
+		return ctx.Err() == nil, err
+	}
+	defer res.Body.Close()
+
+	isData := res.StatusCode >= 200 && res.StatusCode < 300
+	for _, status := range dataStatuses {
+		isData = isData || res.StatusCode == status
+	}
+	env := envelope{}
+	if decodeErr := json.NewDecoder(res.Body).Decode(&env); decodeErr != nil && (decodeErr != io.EOF || res.StatusCode >= 300) {
+		if res.StatusCode < 300 {
+			return false, fmt.Errorf("decoding the response of %s %s: %w", method, path, decodeErr)
+		}
+		//not an answer of the API, like a proxy failing
+		apiErr := errorFromStatus(res)
+		return apiErr.Retryable, apiErr
+	}
+	if !isData {
+		if env.Error == nil {
+			apiErr := errorFromStatus(res)
+			return apiErr.Retryable, apiErr
+		}
+		return env.Error.Retryable, errorFromEnvelope(res.StatusCode, env)
+	}
+	if out == nil || len(env.Data) == 0 || string(env.Data) == "null" {
+		return false, nil
+	}
+	if err := json.Unmarshal(env.Data, out); err != nil {
+		return false, fmt.Errorf("decoding the data of %s %s: %w", method, path, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,370 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/client"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//catalog is the items provider
+type catalog struct{}
+
+var catalogItems = map[string]models.Item{
+	"1": {ID: "1", Name: "Lamp", Price: 20},
+	"2": {ID: "2", Name: "Chair", Price: 45.5},
+}
+
+func (c *catalog) Health(ctx context.Context) error {
+	return nil
+}
+func (c *catalog) GetItem(ctx context.Context, id string) (models.Item, error) {
+	item, ok := catalogItems[id]
+	if !ok {
+		return models.Item{}, serviceErrors.ServiceError{Code: serviceErrors.ItemNotFoundOnProviderCode}
+	}
+	return item, nil
+}
+func (c *catalog) GetAllItems(ctx context.Context) ([]models.Item, error) {
+	return []models.Item{catalogItems["1"], catalogItems["2"]}, nil
+}
+
+//newRouter is the router of the service, with carts in an in-process Redis
+func newRouter(t *testing.T) http.Handler {
+	logger := logrus.New()
+	logger.SetLevel(logrus.PanicLevel)
+	redisClient := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+	carts := repository.NewRedisRepository(cache.NewRedisCache(logger, 0, redisClient))
+	svc := service.NewCartService("test", carts, &catalog{})
+	hsvc := health.NewService(health.NewRegistry())
+	return transport.NewHTTPRouter(logger, svc, hsvc, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+}
+
+func newServer(t *testing.T, handler http.Handler) *httptest.Server {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestCartLifecycle(t *testing.T) {
+	c := client.NewClient(newServer(t, newRouter(t)).URL)
+	ctx := context.Background()
+
+	cart, err := c.CreateCart(ctx)
+	if err != nil || cart.ID == "" {
+		t.Fatalf("Unexpected error creating a cart: %v", err)
+	}
+	if _, err = c.AddItem(ctx, cart.ID, "1", 2); err != nil {
+		t.Fatalf("Unexpected error adding an item: %v", err)
+	}
+	if _, err = c.AddItem(ctx, cart.ID, "2", 1); err != nil {
+		t.Fatalf("Unexpected error adding an item: %v", err)
+	}
+	if _, err = c.UpdateQuantity(ctx, cart.ID, "1", 3); err != nil {
+		t.Fatalf("Unexpected error updating an item: %v", err)
+	}
+	got, err := c.GetCart(ctx, cart.ID)
+	if err != nil || len(got.Items) != 2 || got.Items[0] != (viewmodels.Item{ID: "1", Name: "Lamp", Quantity: 3, Price: 20}) {
+		t.Fatalf("Unexpected cart %+v: %v", got, err)
+	}
+	if got, err = c.RemoveItem(ctx, cart.ID, "1"); err != nil || len(got.Items) != 1 {
+		t.Fatalf("Unexpected cart %+v: %v", got, err)
+	}
+	if got, err = c.RemoveAllItems(ctx, cart.ID); err != nil || len(got.Items) != 0 {
+		t.Fatalf("Unexpected cart %+v: %v", got, err)
+	}
+	if err = c.DeleteCart(ctx, cart.ID); err != nil {
+		t.Fatalf("Unexpected error deleting the cart: %v", err)
+	}
+
+	_, err = c.GetCart(ctx, cart.ID)
+	if !errors.Is(err, client.ErrCartNotFound) {
+		t.Fatalf("A missing cart was expected: %v", err)
+	}
+	apiErr := &client.Error{}
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound || apiErr.RequestID == "" || apiErr.Retryable {
+		t.Fatalf("Unexpected error %+v", apiErr)
+	}
+}
+
+func TestItemErrors(t *testing.T) {
+	c := client.NewClient(newServer(t, newRouter(t)).URL)
+	ctx := context.Background()
+	cart, _ := c.CreateCart(ctx)
+
+	if _, err := c.AddItem(ctx, cart.ID, "1", 1); err != nil {
+		t.Fatalf("Unexpected error adding an item: %v", err)
+	}
+	if _, err := c.AddItem(ctx, cart.ID, "1", 1); !errors.Is(err, client.ErrItemAlreadyInCart) {
+		t.Fatalf("The item was expected to be in the cart already: %v", err)
+	}
+	if _, err := c.UpdateQuantity(ctx, cart.ID, "2", 1); !errors.Is(err, client.ErrItemNotFound) {
+		t.Fatalf("The item was expected not to be in the cart: %v", err)
+	}
+
+	_, err := c.AddItem(ctx, cart.ID, "2", 0)
+	apiErr := &client.Error{}
+	if !errors.Is(err, client.ErrBadRequest) || !errors.As(err, &apiErr) {
+		t.Fatalf("A bad request was expected: %v", err)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Pointer != "/quantity" || apiErr.Details[0].Rule != viewmodels.RuleMin {
+		t.Fatalf("Unexpected details %+v", apiErr.Details)
+	}
+}
+
+func TestItems(t *testing.T) {
+	c := client.NewClient(newServer(t, newRouter(t)).URL)
+	ctx := context.Background()
+
+	items, err := c.GetAvailableItems(ctx)
+	if err != nil || len(items) != 2 {
+		t.Fatalf("Unexpected items %+v: %v", items, err)
+	}
+	item, err := c.GetItem(ctx, "2")
+	if err != nil || item.Name != "Chair" || item.Price != 45.5 {
+		t.Fatalf("Unexpected item %+v: %v", item, err)
+	}
+	if _, err := c.GetItem(ctx, "3"); !errors.Is(err, client.ErrItemNotFoundOnProvider) {
+		t.Fatalf("The item was expected not to be found: %v", err)
+	}
+}
+
+func TestHealth(t *testing.T) {
+	c := client.NewClient(newServer(t, newRouter(t)).URL)
+	ctx := context.Background()
+
+	for name, check := range map[string]func(context.Context) (viewmodels.HealthResponse, error){
+		"health": c.Health,
+		"live":   c.Live,
+		"ready":  c.Ready,
+	} {
+		if hr, err := check(ctx); err != nil || hr.Status != health.StatusUp {
+			t.Fatalf("%s: unexpected response %+v: %v", name, hr, err)
+		}
+	}
+	if _, err := c.HealthHistory(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestTenantAndLanguage(t *testing.T) {
+	os.Setenv(config.TenantsKey, "shop-a,shop-b")
+	defer os.Unsetenv(config.TenantsKey)
+
+	url := newServer(t, newRouter(t)).URL
+	ctx := context.Background()
+
+	cart, _ := client.NewClient(url, client.WithTenant("shop-a")).CreateCart(ctx)
+	if _, err := client.NewClient(url, client.WithTenant("shop-a")).GetCart(ctx, cart.ID); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	_, err := client.NewClient(url, client.WithTenant("shop-b"), client.WithLanguage("es")).GetCart(ctx, cart.ID)
+	apiErr := &client.Error{}
+	if !errors.Is(err, client.ErrCartNotFound) || !errors.As(err, &apiErr) {
+		t.Fatalf("Carts of another tenant were expected not to be found: %v", err)
+	}
+	if expected, _ := viewmodels.Message("es", serviceErrors.CartNotFoundCode); apiErr.Description != expected {
+		t.Fatalf("Unexpected description %q", apiErr.Description)
+	}
+}
+
+//flaky answers a retryable error to the first failures requests
+func flaky(next http.Handler, failures int32, attempts *int32) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(attempts, 1) <= failures {
+			viewmodels.RespondWithError(w, r, serviceErrors.ServiceError{Code: serviceErrors.ExternalApiErrorCode})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRetries(t *testing.T) {
+	attempts := int32(0)
+	c := client.NewClient(newServer(t, flaky(newRouter(t), 2, &attempts)).URL, client.WithRetries(2, time.Millisecond))
+
+	if _, err := c.GetAvailableItems(context.Background()); err != nil {
+		t.Fatalf("The request was expected to succeed once retried: %v", err)
+	}
+	if attempts != 3 {
+		t.Fatalf("Unexpected attempts %d", attempts)
+	}
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	attempts := int32(0)
+	c := client.NewClient(newServer(t, flaky(newRouter(t), 5, &attempts)).URL, client.WithRetries(1, time.Millisecond))
+
+	_, err := c.GetAvailableItems(context.Background())
+	apiErr := &client.Error{}
+	if !errors.Is(err, client.ErrExternalAPI) || !errors.As(err, &apiErr) || !apiErr.Retryable {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if attempts != 2 {
+		t.Fatalf("Unexpected attempts %d", attempts)
+	}
+}
+
+func TestNotIdempotentRequestsNotRetried(t *testing.T) {
+	attempts := int32(0)
+	c := client.NewClient(newServer(t, flaky(newRouter(t), 1, &attempts)).URL, client.WithRetries(2, time.Millisecond))
+
+	if _, err := c.CreateCart(context.Background()); !errors.Is(err, client.ErrExternalAPI) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if attempts != 1 {
+		t.Fatalf("Unexpected attempts %d", attempts)
+	}
+}
+
+//lostResponse serves the first DELETE but answers it with a gateway timeout, as if the response was lost
+func lostResponse(next http.Handler) http.Handler {
+	lost := int32(0)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete && atomic.AddInt32(&lost, 1) == 1 {
+			next.ServeHTTP(httptest.NewRecorder(), r)
+			w.WriteHeader(http.StatusGatewayTimeout)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func TestRetriedDeleteAlreadyDone(t *testing.T) {
+	router := newRouter(t)
+	ctx := context.Background()
+	setup := client.NewClient(newServer(t, router).URL)
+	c := client.NewClient(newServer(t, lostResponse(router)).URL, client.WithRetries(1, time.Millisecond))
+
+	cart, _ := setup.CreateCart(ctx)
+	setup.AddItem(ctx, cart.ID, "1", 1)
+	setup.AddItem(ctx, cart.ID, "2", 1)
+	got, err := c.RemoveItem(ctx, cart.ID, "1")
+	if err != nil || len(got.Items) != 1 || got.Items[0].ID != "2" {
+		t.Fatalf("The item was expected to be removed: %+v %v", got, err)
+	}
+
+	c = client.NewClient(newServer(t, lostResponse(router)).URL, client.WithRetries(1, time.Millisecond))
+	if err := c.DeleteCart(ctx, cart.ID); err != nil {
+		t.Fatalf("The cart was expected to be deleted: %v", err)
+	}
+
+	//a missing cart is still an error when the request was not retried
+	if err := c.DeleteCart(ctx, cart.ID); !errors.Is(err, client.ErrCartNotFound) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestGatewayErrorsRetried(t *testing.T) {
+	attempts := int32(0)
+	router := newRouter(t)
+	srv := newServer(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>bad gateway</html>"))
+			return
+		}
+		router.ServeHTTP(w, r)
+	}))
+	c := client.NewClient(srv.URL, client.WithRetries(1, time.Millisecond))
+
+	if _, err := c.Live(context.Background()); err != nil || attempts != 2 {
+		t.Fatalf("Unexpected attempts %d: %v", attempts, err)
+	}
+}
+
+func TestContextCanceled(t *testing.T) {
+	attempts := int32(0)
+	c := client.NewClient(newServer(t, flaky(newRouter(t), 5, &attempts)).URL, client.WithRetries(5, time.Second))
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	if _, err := c.GetAvailableItems(ctx); err == nil {
+		t.Fatalf("Error was expected")
+	}
+	if time.Since(start) > time.Second || attempts != 1 {
+		t.Fatalf("Retries were expected to stop with the context")
+	}
+}
+
+//countingClient is an HTTPClient counting the requests it sends
+type countingClient struct {
+	requests int
+}
+
+func (c *countingClient) Do(req *http.Request) (*http.Response, error) {
+	c.requests++
+	return http.DefaultClient.Do(req)
+}
+
+func TestPluggableHTTPClient(t *testing.T) {
+	httpClient := &countingClient{}
+	c := client.NewClient(newServer(t, newRouter(t)).URL, client.WithHTTPClient(httpClient))
+
+	if _, err := c.CreateCart(context.Background()); err != nil || httpClient.requests != 1 {
+		t.Fatalf("The request was expected to go through the HTTP client: %v", err)
+	}
+}
+
+func TestSharedCart(t *testing.T) {
+	c := client.NewClient(newServer(t, newRouter(t)).URL)
+	ctx := context.Background()
+	cart, _ := c.CreateCart(ctx)
+
+	shared, err := c.SharedCart(ctx, cart.ID, "alice")
+	if err != nil {
+		t.Fatalf("Unexpected error joining the cart: %v", err)
+	}
+	defer shared.Close()
+	receiveCart(t, shared, 0)
+
+	if err := shared.Send(viewmodels.CollabCommand{Type: viewmodels.CollabCommandAdd, ItemID: "1", Quantity: 2}); err != nil {
+		t.Fatalf("Unexpected error sending a command: %v", err)
+	}
+	receiveCart(t, shared, 1)
+
+	if _, err := c.SharedCart(ctx, "missing", ""); !errors.Is(err, client.ErrCartNotFound) {
+		t.Fatalf("A missing cart was expected: %v", err)
+	}
+}
+
+//receiveCart waits for the cart, skipping changes of viewers
+func receiveCart(t *testing.T, shared client.SharedCart, items int) {
+	for {
+		msg, err := shared.Receive()
+		if err != nil {
+			t.Fatalf("Unexpected error receiving: %v", err)
+		}
+		if msg.Type != viewmodels.CollabMessageCart {
+			continue
+		}
+		if len(msg.Cart.Items) != items {
+			t.Fatalf("Unexpected cart %+v", msg.Cart)
+		}
+		return
+	}
+}
@@ -0,0 +1,69 @@
+package client
+
+import (
+	"fmt"
+	"net/http"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+)
+
+//Error is an error answered by the API
+type Error struct {
+	StatusCode int
+	//Code is the error code of the API, empty when the response did not come from it
+	Code        string
+	Description string
+	//Retryable errors may go away when the request is retried
+	Retryable bool
+	//Details are the inputs of the request that broke a rule
+	Details   []viewmodels.Violation
+	RequestID string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d %s: %s", e.StatusCode, e.Code, e.Description)
+}
+
+//Is matches errors with the same code, errors.Is(err, client.ErrCartNotFound) tells a missing cart
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+//Errors of the API to match with errors.Is
+var (
+	ErrBadRequest             = &Error{Code: serviceErrors.BadRequestCode}
+	ErrCartNotFound           = &Error{Code: serviceErrors.CartNotFoundCode}
+	ErrItemNotFound           = &Error{Code: serviceErrors.ItemNotFoundCode}
+	ErrItemNotFoundOnProvider = &Error{Code: serviceErrors.ItemNotFoundOnProviderCode}
+	ErrItemAlreadyInCart      = &Error{Code: serviceErrors.ItemAlreadyInCartCode}
+	ErrExternalAPI            = &Error{Code: serviceErrors.ExternalApiErrorCode}
+	ErrCache                  = &Error{Code: serviceErrors.CacheErrorCode}
+	ErrInternal               = &Error{Code: serviceErrors.InternalCode}
+)
+
+//errorFromEnvelope is the error answered in a BaseResponse
+func errorFromEnvelope(status int, env envelope) *Error {
+	return &Error{
+		StatusCode:  status,
+		Code:        env.Error.Code,
+		Description: env.Error.Description,
+		Retryable:   env.Error.Retryable,
+		Details:     env.Error.Details,
+		RequestID:   env.Meta.RequestID,
+	}
+}
+
+//errorFromStatus is the error of a response without an error in the envelope, only gateway errors are worth retrying
+func errorFromStatus(res *http.Response) *Error {
+	return &Error{
+		StatusCode:  res.StatusCode,
+		Description: http.StatusText(res.StatusCode),
+		Retryable: res.StatusCode == http.StatusBadGateway ||
+			res.StatusCode == http.StatusServiceUnavailable ||
+			res.StatusCode == http.StatusGatewayTimeout,
+		RequestID: res.Header.Get(requestid.HeaderKey),
+	}
+}
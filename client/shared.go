@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/gorilla/websocket"
+)
+
+//SharedCart is a connection to the WebSocket of a shared cart.
+//The cart and the viewers are received on joining, then every update and change of viewers follow.
+type SharedCart interface {
+	//Send sends a command, the updated cart is received by every viewer
+	Send(cmd viewmodels.CollabCommand) error
+	//Receive waits for the next message, errors of the commands sent come as CollabMessageError messages.
+	//Viewers falling behind are disconnected with websocket.CloseTryAgainLater, join again to get the cart.
+	Receive() (viewmodels.CollabMessage, error)
+	Close() error
+}
+
+type sharedCart struct {
+	conn *websocket.Conn
+}
+
+func (c *client) SharedCart(ctx context.Context, cartID, viewer string) (SharedCart, error) {
+	u := strings.Replace(c.baseURL, "http", "ws", 1) + cartPath(cartID, "ws")
+	if viewer != "" {
+		u += "?viewer=" + url.QueryEscape(viewer)
+	}
+	header := http.Header{}
+	if c.tenant != "" {
+		header.Set(tenant.HeaderKey, c.tenant)
+	}
+	if c.language != "" {
+		header.Set("Accept-Language", c.language)
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		header.Set(requestid.HeaderKey, id)
+	}
+
+	conn, res, err := websocket.DefaultDialer.DialContext(ctx, u, header)
+	if err != nil {
+		//the API answers a missing cart before upgrading
+		if res != nil && res.StatusCode != http.StatusSwitchingProtocols {
+			defer res.Body.Close()
+			env := envelope{}
+			if json.NewDecoder(res.Body).Decode(&env) == nil && env.Error != nil {
+				return nil, errorFromEnvelope(res.StatusCode, env)
+			}
+			return nil, errorFromStatus(res)
+		}
+		return nil, err
+	}
+	return &sharedCart{conn: conn}, nil
+}
+
+func (s *sharedCart) Send(cmd viewmodels.CollabCommand) error {
+	return s.conn.WriteJSON(cmd)
+}
+
+func (s *sharedCart) Receive() (viewmodels.CollabMessage, error) {
+	msg := viewmodels.CollabMessage{}
+	err := s.conn.ReadJSON(&msg)
+	return msg, err
+}
+
+func (s *sharedCart) Close() error {
+	return s.conn.Close()
+}
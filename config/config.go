@@ -1,6 +1,13 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
 
 var serviceVersion = "local"
 
@@ -8,7 +15,59 @@ const (
 	RedisServerKey   = "REDIS_SERVER"
 	RedisPasswordKey = "REDIS_PASSWORD"
 
+	RedisModeKey                  = "REDIS_MODE"
+	RedisSentinelMasterKey        = "REDIS_SENTINEL_MASTER"
+	RedisSentinelPasswordKey      = "REDIS_SENTINEL_PASSWORD"
+	RedisUsernameKey              = "REDIS_USERNAME"
+	RedisDBKey                    = "REDIS_DB"
+	RedisTLSKey                   = "REDIS_TLS"
+	RedisTLSCAFileKey             = "REDIS_TLS_CA_FILE"
+	RedisTLSInsecureSkipVerifyKey = "REDIS_TLS_INSECURE_SKIP_VERIFY"
+	RedisPoolSizeKey              = "REDIS_POOL_SIZE"
+	RedisDialTimeoutKey           = "REDIS_DIAL_TIMEOUT"
+	RedisReadTimeoutKey           = "REDIS_READ_TIMEOUT"
+	RedisWriteTimeoutKey          = "REDIS_WRITE_TIMEOUT"
+
 	HTTP_PORT = "HTTP_PORT"
+	GRPC_PORT = "GRPC_PORT"
+
+	LegacyRoutesSunsetKey     = "LEGACY_ROUTES_SUNSET"
+	defaultLegacyRoutesSunset = "2027-04-30"
+
+	TracingExporterKey = "TRACING_EXPORTER"
+
+	AccessLogLevelKey      = "ACCESS_LOG_LEVEL"
+	AccessLogSampleRateKey = "ACCESS_LOG_SAMPLE_RATE"
+
+	CacheLogLevelKey         = "CACHE_LOG_LEVEL"
+	CacheLogValuesKey        = "CACHE_LOG_VALUES"
+	CacheLogMaxValueBytesKey = "CACHE_LOG_MAX_VALUE_BYTES"
+	CacheLogRedactFieldsKey  = "CACHE_LOG_REDACT_FIELDS"
+	CacheLogDisabledKey      = "CACHE_LOG_DISABLED"
+
+	CacheKeyPrefixKey  = "CACHE_KEY_PREFIX"
+	CacheLegacyKeysKey = "CACHE_LEGACY_KEYS"
+	CacheCodecKey      = "CACHE_CODEC"
+	TenantSourceKey    = "TENANT_SOURCE"
+	TenantsKey         = "TENANTS"
+
+	CacheEncryptionKeysKey          = "CACHE_ENCRYPTION_KEYS"
+	CacheEncryptionKeysFileKey      = "CACHE_ENCRYPTION_KEYS_FILE"
+	CacheEncryptionReadPlaintextKey = "CACHE_ENCRYPTION_READ_PLAINTEXT"
+
+	HealthCheckTimeoutKey    = "HEALTH_CHECK_TIMEOUT"
+	HealthDegradedLatencyKey = "HEALTH_DEGRADED_LATENCY"
+	HealthCacheTTLKey        = "HEALTH_CACHE_TTL"
+	HealthMonitorIntervalKey = "HEALTH_MONITOR_INTERVAL"
+	HealthHistorySizeKey     = "HEALTH_HISTORY_SIZE"
+
+	ProblemTypeBaseURLKey = "PROBLEM_TYPE_BASE_URL"
+
+	OpenAPIValidationKey        = "OPENAPI_VALIDATION"
+	OpenAPIValidateResponsesKey = "OPENAPI_VALIDATE_RESPONSES"
+
+	CartStorageKey = "CART_STORAGE"
+	SQLitePathKey  = "SQLITE_PATH"
 )
 
 func GetVersion() string {
@@ -26,3 +85,216 @@ func GetEnvString(key, defaultValue string) string {
 func GetPort() string {
 	return GetEnvString(HTTP_PORT, "8080")
 }
+
+func GetGRPCPort() string {
+	return GetEnvString(GRPC_PORT, "9090")
+}
+
+//GetLegacyRoutesSunset is the date (YYYY-MM-DD) after which unversioned routes may be removed
+func GetLegacyRoutesSunset() time.Time {
+	sunset, err := time.Parse("2006-01-02", GetEnvString(LegacyRoutesSunsetKey, defaultLegacyRoutesSunset))
+	if err != nil {
+		sunset, _ = time.Parse("2006-01-02", defaultLegacyRoutesSunset)
+	}
+	return sunset
+}
+
+//GetTracingExporter is where spans are sent: none, stdout or otlp
+func GetTracingExporter() string {
+	return GetEnvString(TracingExporterKey, "none")
+}
+
+//GetEnvInt parses an integer variable, defaultValue when unset or invalid
+func GetEnvInt(key string, defaultValue int) int {
+	val, err := strconv.Atoi(GetEnvString(key, strconv.Itoa(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+//GetEnvBool parses a boolean variable (1, t, true, 0, f, false...), defaultValue when unset or invalid
+func GetEnvBool(key string, defaultValue bool) bool {
+	val, err := strconv.ParseBool(GetEnvString(key, strconv.FormatBool(defaultValue)))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+//GetEnvDuration parses a duration variable such as 500ms or 3s, defaultValue when unset or invalid
+func GetEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	val, err := time.ParseDuration(GetEnvString(key, defaultValue.String()))
+	if err != nil {
+		return defaultValue
+	}
+	return val
+}
+
+//GetEnvList splits a comma separated variable, dropping empty elements
+func GetEnvList(key string, defaultValue []string) []string {
+	val := os.Getenv(key)
+	if val == "" {
+		return defaultValue
+	}
+	list := []string{}
+	for _, element := range strings.Split(val, ",") {
+		if element = strings.TrimSpace(element); element != "" {
+			list = append(list, element)
+		}
+	}
+	return list
+}
+
+//GetEnvLevel parses the logrus level entries are written at, defaultValue when unset or invalid.
+//panic and fatal are error: logrus panics on panic entries and drops fatal ones.
+func GetEnvLevel(key string, defaultValue logrus.Level) logrus.Level {
+	level, err := logrus.ParseLevel(GetEnvString(key, defaultValue.String()))
+	if err != nil {
+		return defaultValue
+	}
+	if level < logrus.ErrorLevel {
+		return logrus.ErrorLevel
+	}
+	return level
+}
+
+//GetAccessLogLevel is the level access log entries are written at, info when unset or invalid
+func GetAccessLogLevel() logrus.Level {
+	return GetEnvLevel(AccessLogLevelKey, logrus.InfoLevel)
+}
+
+//GetAccessLogSampleRate is the fraction of requests that get an access log entry, from 0 to 1
+func GetAccessLogSampleRate() float64 {
+	rate, err := strconv.ParseFloat(GetEnvString(AccessLogSampleRateKey, "1"), 64)
+	if err != nil || rate > 1 {
+		return 1
+	}
+	if rate < 0 {
+		return 0
+	}
+	return rate
+}
+
+//GetCacheLogLevel is the level cache operations are logged at, debug when unset or invalid
+func GetCacheLogLevel() logrus.Level {
+	return GetEnvLevel(CacheLogLevelKey, logrus.DebugLevel)
+}
+
+//GetCacheLogValues is how values written to the cache are logged: omit, hash, truncate or full
+func GetCacheLogValues() string {
+	return GetEnvString(CacheLogValuesKey, "hash")
+}
+
+//GetCacheLogMaxValueBytes is where logged values are truncated
+func GetCacheLogMaxValueBytes() int {
+	max, err := strconv.Atoi(GetEnvString(CacheLogMaxValueBytesKey, "256"))
+	if err != nil || max < 0 {
+		return 256
+	}
+	return max
+}
+
+//GetCacheLogRedactFields are the JSON fields hidden from logged cache values
+func GetCacheLogRedactFields() []string {
+	return GetEnvList(CacheLogRedactFieldsKey, []string{"email", "address", "phone"})
+}
+
+//GetCacheLogDisabled are the cache operations (set, get, del, ping) that are not logged
+func GetCacheLogDisabled() []string {
+	return GetEnvList(CacheLogDisabledKey, []string{})
+}
+
+//GetCartStorage is the backend carts are stored on: redis or sqlite
+func GetCartStorage() string {
+	return GetEnvString(CartStorageKey, "redis")
+}
+
+//GetSQLitePath is the database file used by the sqlite cart storage
+func GetSQLitePath() string {
+	return GetEnvString(SQLitePathKey, "carts.db")
+}
+
+//GetCacheKeyPrefix is the prefix of every key the service writes to Redis
+func GetCacheKeyPrefix() string {
+	return GetEnvString(CacheKeyPrefixKey, "")
+}
+
+//GetCacheLegacyKeys tells whether carts stored in Redis before keys were namespaced are still read, as carts of the default tenant
+func GetCacheLegacyKeys() bool {
+	return GetEnvBool(CacheLegacyKeysKey, true)
+}
+
+//GetTenantSource is where the tenant of a request is read from: header (X-Tenant-ID) or host (first label)
+func GetTenantSource() string {
+	return GetEnvString(TenantSourceKey, "header")
+}
+
+//GetTenants are the accepted tenants. When empty only the default tenant is served,
+//unless the tenant comes from the host, where any valid one is accepted.
+func GetTenants() []string {
+	return GetEnvList(TenantsKey, []string{})
+}
+
+//GetCacheCodec is how values are written to the cache: json, msgpack or gzip-json
+func GetCacheCodec() string {
+	return GetEnvString(CacheCodecKey, "json")
+}
+
+//GetCacheEncryptionKeys are the keys cached values are encrypted with, as "id:base64key" with the primary one first.
+//Values are not encrypted when neither these keys nor a keys file are set.
+func GetCacheEncryptionKeys() string {
+	return GetEnvString(CacheEncryptionKeysKey, "")
+}
+
+//GetCacheEncryptionKeysFile is a file with the encryption keys, one per line, read instead of CACHE_ENCRYPTION_KEYS
+func GetCacheEncryptionKeysFile() string {
+	return GetEnvString(CacheEncryptionKeysFileKey, "")
+}
+
+//GetCacheEncryptionReadPlaintext tells whether values written before encryption was enabled are still read.
+//Turn it off once reencrypt-cache has encrypted them.
+func GetCacheEncryptionReadPlaintext() bool {
+	return GetEnvBool(CacheEncryptionReadPlaintextKey, true)
+}
+
+//GetHealthCheckTimeout is the deadline of a whole run of the health checks
+func GetHealthCheckTimeout() time.Duration {
+	return GetEnvDuration(HealthCheckTimeoutKey, 2*time.Second)
+}
+
+//GetHealthDegradedLatency is the latency above which a dependency that answered is degraded
+func GetHealthDegradedLatency() time.Duration {
+	return GetEnvDuration(HealthDegradedLatencyKey, 500*time.Millisecond)
+}
+
+//GetHealthCacheTTL is how long a health report is served before checking again
+func GetHealthCacheTTL() time.Duration {
+	return GetEnvDuration(HealthCacheTTLKey, time.Second)
+}
+
+//GetHealthMonitorInterval is how often the dependencies are checked in the background, 0 disables it
+func GetHealthMonitorInterval() time.Duration {
+	return GetEnvDuration(HealthMonitorIntervalKey, 10*time.Second)
+}
+
+//GetHealthHistorySize is how many health transitions are kept
+func GetHealthHistorySize() int {
+	return GetEnvInt(HealthHistorySizeKey, 100)
+}
+
+//GetProblemTypeBaseURL is where the type URIs of problem+json errors point to, the error code is appended
+func GetProblemTypeBaseURL() string {
+	return GetEnvString(ProblemTypeBaseURLKey, "/problems/")
+}
+
+//GetOpenAPIValidation tells whether requests are checked against the OpenAPI spec before reaching the controllers
+func GetOpenAPIValidation() bool {
+	return GetEnvBool(OpenAPIValidationKey, false)
+}
+
+//GetOpenAPIValidateResponses tells whether responses are checked against the OpenAPI spec too.
+//A response not matching it panics, so it is meant for tests and development.
+func GetOpenAPIValidateResponses() bool {
+	return GetEnvBool(OpenAPIValidateResponsesKey, false)
+}
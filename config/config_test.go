@@ -3,8 +3,11 @@ package config_test
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestGetVersion(t *testing.T) {
@@ -45,3 +48,297 @@ func TestGetPortDefault(t *testing.T) {
 		t.Fatalf("Unexpected Port")
 	}
 }
+
+func TestGetGRPCPort(t *testing.T) {
+	os.Setenv(config.GRPC_PORT, "9001")
+	defer os.Unsetenv(config.GRPC_PORT)
+
+	if config.GetGRPCPort() != "9001" {
+		t.Fatalf("Unexpected Port")
+	}
+}
+
+func TestGetGRPCPortDefault(t *testing.T) {
+	os.Unsetenv(config.GRPC_PORT)
+	if config.GetGRPCPort() != "9090" {
+		t.Fatalf("Unexpected Port")
+	}
+}
+
+func TestGetLegacyRoutesSunset(t *testing.T) {
+	os.Setenv(config.LegacyRoutesSunsetKey, "2030-01-02")
+	defer os.Unsetenv(config.LegacyRoutesSunsetKey)
+
+	if config.GetLegacyRoutesSunset().Format("2006-01-02") != "2030-01-02" {
+		t.Fatalf("Unexpected Sunset")
+	}
+}
+
+func TestGetLegacyRoutesSunsetInvalid(t *testing.T) {
+	os.Setenv(config.LegacyRoutesSunsetKey, "someday")
+	defer os.Unsetenv(config.LegacyRoutesSunsetKey)
+
+	if config.GetLegacyRoutesSunset().Format("2006-01-02") != "2027-04-30" {
+		t.Fatalf("Unexpected Sunset")
+	}
+}
+
+func TestGetTracingExporter(t *testing.T) {
+	os.Setenv(config.TracingExporterKey, "otlp")
+	defer os.Unsetenv(config.TracingExporterKey)
+
+	if config.GetTracingExporter() != "otlp" {
+		t.Fatalf("Unexpected Exporter")
+	}
+}
+
+func TestGetTracingExporterDefault(t *testing.T) {
+	os.Unsetenv(config.TracingExporterKey)
+	if config.GetTracingExporter() != "none" {
+		t.Fatalf("Unexpected Exporter")
+	}
+}
+
+func TestGetAccessLogLevel(t *testing.T) {
+	os.Setenv(config.AccessLogLevelKey, "debug")
+	defer os.Unsetenv(config.AccessLogLevelKey)
+
+	if config.GetAccessLogLevel() != logrus.DebugLevel {
+		t.Fatalf("Unexpected Level")
+	}
+}
+
+func TestGetAccessLogLevelInvalid(t *testing.T) {
+	os.Setenv(config.AccessLogLevelKey, "loud")
+	defer os.Unsetenv(config.AccessLogLevelKey)
+
+	if config.GetAccessLogLevel() != logrus.InfoLevel {
+		t.Fatalf("Unexpected Level")
+	}
+}
+
+func TestGetAccessLogLevelNeverPanics(t *testing.T) {
+	for _, level := range []string{"panic", "fatal"} {
+		os.Setenv(config.AccessLogLevelKey, level)
+		if config.GetAccessLogLevel() != logrus.ErrorLevel {
+			t.Fatalf("Unexpected Level for %s", level)
+		}
+	}
+	os.Unsetenv(config.AccessLogLevelKey)
+}
+
+func TestGetAccessLogSampleRate(t *testing.T) {
+	os.Setenv(config.AccessLogSampleRateKey, "0.25")
+	defer os.Unsetenv(config.AccessLogSampleRateKey)
+
+	if config.GetAccessLogSampleRate() != 0.25 {
+		t.Fatalf("Unexpected Sample Rate")
+	}
+}
+
+func TestGetAccessLogSampleRateDefault(t *testing.T) {
+	os.Unsetenv(config.AccessLogSampleRateKey)
+	if config.GetAccessLogSampleRate() != 1 {
+		t.Fatalf("Unexpected Sample Rate")
+	}
+}
+
+func TestGetEnvList(t *testing.T) {
+	os.Setenv("TEST_LIST", "a, b,,c ")
+	defer os.Unsetenv("TEST_LIST")
+
+	list := config.GetEnvList("TEST_LIST", nil)
+	if len(list) != 3 || list[0] != "a" || list[1] != "b" || list[2] != "c" {
+		t.Fatalf("Unexpected List %v", list)
+	}
+}
+
+func TestGetEnvListDefault(t *testing.T) {
+	os.Unsetenv("TEST_LIST")
+	list := config.GetEnvList("TEST_LIST", []string{"default"})
+	if len(list) != 1 || list[0] != "default" {
+		t.Fatalf("Unexpected List %v", list)
+	}
+}
+
+func TestGetCacheLogDefaults(t *testing.T) {
+	if config.GetCacheLogLevel() != logrus.DebugLevel {
+		t.Fatalf("Unexpected Level")
+	}
+	if config.GetCacheLogValues() != "hash" {
+		t.Fatalf("Unexpected Values")
+	}
+	if config.GetCacheLogMaxValueBytes() != 256 {
+		t.Fatalf("Unexpected Max Value Bytes")
+	}
+	if len(config.GetCacheLogRedactFields()) != 3 {
+		t.Fatalf("Unexpected Redact Fields")
+	}
+	if len(config.GetCacheLogDisabled()) != 0 {
+		t.Fatalf("Unexpected Disabled Operations")
+	}
+}
+
+func TestGetCacheLogMaxValueBytesInvalid(t *testing.T) {
+	os.Setenv(config.CacheLogMaxValueBytesKey, "-1")
+	defer os.Unsetenv(config.CacheLogMaxValueBytesKey)
+
+	if config.GetCacheLogMaxValueBytes() != 256 {
+		t.Fatalf("Unexpected Max Value Bytes")
+	}
+}
+
+func TestGetCartStorage(t *testing.T) {
+	os.Setenv(config.CartStorageKey, "sqlite")
+	defer os.Unsetenv(config.CartStorageKey)
+
+	if config.GetCartStorage() != "sqlite" {
+		t.Fatalf("Unexpected Storage")
+	}
+}
+
+func TestGetCartStorageDefault(t *testing.T) {
+	os.Unsetenv(config.CartStorageKey)
+	if config.GetCartStorage() != "redis" {
+		t.Fatalf("Unexpected Storage")
+	}
+	if config.GetSQLitePath() != "carts.db" {
+		t.Fatalf("Unexpected SQLite Path")
+	}
+}
+
+func TestGetEnvInt(t *testing.T) {
+	os.Setenv("TEST_INT", "3")
+	defer os.Unsetenv("TEST_INT")
+
+	if config.GetEnvInt("TEST_INT", 1) != 3 {
+		t.Fatalf("Unexpected Int")
+	}
+	os.Setenv("TEST_INT", "three")
+	if config.GetEnvInt("TEST_INT", 1) != 1 {
+		t.Fatalf("Unexpected Int")
+	}
+}
+
+func TestGetEnvBool(t *testing.T) {
+	os.Setenv("TEST_BOOL", "true")
+	defer os.Unsetenv("TEST_BOOL")
+
+	if !config.GetEnvBool("TEST_BOOL", false) {
+		t.Fatalf("Unexpected Bool")
+	}
+	os.Setenv("TEST_BOOL", "yes please")
+	if config.GetEnvBool("TEST_BOOL", false) {
+		t.Fatalf("Unexpected Bool")
+	}
+}
+
+func TestGetEnvDuration(t *testing.T) {
+	os.Setenv("TEST_DURATION", "250ms")
+	defer os.Unsetenv("TEST_DURATION")
+
+	if config.GetEnvDuration("TEST_DURATION", time.Second) != 250*time.Millisecond {
+		t.Fatalf("Unexpected Duration")
+	}
+	os.Setenv("TEST_DURATION", "soon")
+	if config.GetEnvDuration("TEST_DURATION", time.Second) != time.Second {
+		t.Fatalf("Unexpected Duration")
+	}
+}
+
+func TestGetTenantDefaults(t *testing.T) {
+	if config.GetCacheKeyPrefix() != "" {
+		t.Fatalf("Unexpected Key Prefix")
+	}
+	if !config.GetCacheLegacyKeys() {
+		t.Fatalf("Legacy keys are expected to be read by default")
+	}
+	if config.GetTenantSource() != "header" {
+		t.Fatalf("Unexpected Tenant Source")
+	}
+	if len(config.GetTenants()) != 0 {
+		t.Fatalf("Unexpected Tenants")
+	}
+}
+
+func TestGetTenants(t *testing.T) {
+	os.Setenv(config.TenantsKey, "shop-a,shop-b")
+	defer os.Unsetenv(config.TenantsKey)
+
+	if tenants := config.GetTenants(); len(tenants) != 2 || tenants[1] != "shop-b" {
+		t.Fatalf("Unexpected Tenants")
+	}
+}
+
+func TestGetCacheCodec(t *testing.T) {
+	os.Setenv(config.CacheCodecKey, "msgpack")
+	defer os.Unsetenv(config.CacheCodecKey)
+
+	if config.GetCacheCodec() != "msgpack" {
+		t.Fatalf("Unexpected Codec")
+	}
+	os.Unsetenv(config.CacheCodecKey)
+	if config.GetCacheCodec() != "json" {
+		t.Fatalf("Unexpected Codec")
+	}
+}
+
+func TestGetCacheEncryptionKeys(t *testing.T) {
+	if config.GetCacheEncryptionKeys() != "" || config.GetCacheEncryptionKeysFile() != "" {
+		t.Fatalf("Encryption was not expected by default")
+	}
+	os.Setenv(config.CacheEncryptionKeysFileKey, "/run/secrets/cache-keys")
+	defer os.Unsetenv(config.CacheEncryptionKeysFileKey)
+
+	if config.GetCacheEncryptionKeysFile() != "/run/secrets/cache-keys" {
+		t.Fatalf("Unexpected Keys File")
+	}
+	if !config.GetCacheEncryptionReadPlaintext() {
+		t.Fatalf("Plaintext values are expected to be read by default")
+	}
+}
+
+func TestGetProblemTypeBaseURL(t *testing.T) {
+	if config.GetProblemTypeBaseURL() != "/problems/" {
+		t.Fatalf("Unexpected Problem Type Base URL")
+	}
+	os.Setenv(config.ProblemTypeBaseURLKey, "https://errors.example.com/")
+	defer os.Unsetenv(config.ProblemTypeBaseURLKey)
+
+	if config.GetProblemTypeBaseURL() != "https://errors.example.com/" {
+		t.Fatalf("Unexpected Problem Type Base URL")
+	}
+}
+
+func TestGetOpenAPIValidation(t *testing.T) {
+	if config.GetOpenAPIValidation() || config.GetOpenAPIValidateResponses() {
+		t.Fatalf("OpenAPI validation is expected to be off by default")
+	}
+	os.Setenv(config.OpenAPIValidationKey, "true")
+	defer os.Unsetenv(config.OpenAPIValidationKey)
+	os.Setenv(config.OpenAPIValidateResponsesKey, "true")
+	defer os.Unsetenv(config.OpenAPIValidateResponsesKey)
+
+	if !config.GetOpenAPIValidation() || !config.GetOpenAPIValidateResponses() {
+		t.Fatalf("Unexpected OpenAPI Validation")
+	}
+}
+
+func TestGetHealthDefaults(t *testing.T) {
+	if config.GetHealthCheckTimeout() != 2*time.Second ||
+		config.GetHealthDegradedLatency() != 500*time.Millisecond ||
+		config.GetHealthCacheTTL() != time.Second ||
+		config.GetHealthMonitorInterval() != 10*time.Second ||
+		config.GetHealthHistorySize() != 100 {
+		t.Fatalf("Unexpected Health defaults")
+	}
+}
+
+func TestGetHealthMonitorInterval(t *testing.T) {
+	os.Setenv(config.HealthMonitorIntervalKey, "0")
+	defer os.Unsetenv(config.HealthMonitorIntervalKey)
+
+	if config.GetHealthMonitorInterval() != 0 {
+		t.Fatalf("Unexpected Monitor Interval")
+	}
+}
@@ -1,17 +1,17 @@
 package controller
 
 import (
-	"encoding/json"
-	"log"
 	"net/http"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
 	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 )
 
 type CartController struct {
 	Service service.CartService
+	Logger  *logrus.Logger
 }
 
 //CreateCart creates a cart on the DB
@@ -19,7 +19,7 @@ func (c *CartController) CreateCart(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := c.Service.CreateCart(r.Context())
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 
@@ -35,7 +35,7 @@ func (c *CartController) GetCart(w http.ResponseWriter, r *http.Request) {
 	cartID := vars["cart_id"]
 	cart, err := c.Service.GetCart(r.Context(), cartID)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 	response := viewmodels.CartResponse{
@@ -51,7 +51,7 @@ func (c *CartController) DeleteCart(w http.ResponseWriter, r *http.Request) {
 
 	err := c.Service.DeleteCart(r.Context(), cartID)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 	viewmodels.RespondWithData(w, http.StatusAccepted, nil)
@@ -63,17 +63,15 @@ func (c *CartController) AddItem(w http.ResponseWriter, r *http.Request) {
 	cartID := vars["cart_id"]
 
 	vm := viewmodels.AddItemToCartRequest{}
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-	err := decoder.Decode(&vm)
+	err := viewmodels.DecodeRequest(r.Body, &vm)
 	if err != nil {
-		log.Printf("Error decoding body: %v", err)
-		viewmodels.RespondWithError(w, viewmodels.StandardBadBodyRequest)
+		c.Logger.WithContext(r.Context()).WithError(err).Warn("bad_body")
+		viewmodels.RespondWithError(w, r, err)
 		return
 	}
 	cart, err := c.Service.AddItemToCart(r.Context(), cartID, vm.ID, vm.Quantity)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 
@@ -90,18 +88,16 @@ func (c *CartController) UpdateQuantity(w http.ResponseWriter, r *http.Request)
 	itemID := vars["item_id"]
 
 	vm := viewmodels.ModifyItemQuantityRequest{}
-	decoder := json.NewDecoder(r.Body)
-	decoder.DisallowUnknownFields()
-	err := decoder.Decode(&vm)
+	err := viewmodels.DecodeRequest(r.Body, &vm)
 	if err != nil {
-		log.Printf("Error decoding body: %v", err)
-		viewmodels.RespondWithError(w, viewmodels.StandardBadBodyRequest)
+		c.Logger.WithContext(r.Context()).WithError(err).Warn("bad_body")
+		viewmodels.RespondWithError(w, r, err)
 		return
 	}
 
 	cart, err := c.Service.ModifyItemInCart(r.Context(), cartID, itemID, vm.Quantity)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 
@@ -119,7 +115,7 @@ func (c *CartController) RemoveItem(w http.ResponseWriter, r *http.Request) {
 
 	cart, err := c.Service.DeleteItemInCart(r.Context(), cartID, itemID)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 
@@ -137,7 +133,7 @@ func (c *CartController) RemoveAllItems(w http.ResponseWriter, r *http.Request)
 
 	cart, err := c.Service.DeleteAllItemsInCart(r.Context(), cartID)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 
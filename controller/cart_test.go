@@ -6,17 +6,19 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/sirupsen/logrus"
 )
 
 func TestCreateCartOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPost, "/cart")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -29,8 +31,9 @@ func TestCreateCartOk(t *testing.T) {
 	}
 }
 func TestCreateCartError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPost, "/cart")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -44,8 +47,9 @@ func TestCreateCartError(t *testing.T) {
 }
 
 func TestGetCartOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/cart/{cart_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -58,8 +62,9 @@ func TestGetCartOk(t *testing.T) {
 	}
 }
 func TestGetCartError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/cart/{cart_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -73,8 +78,9 @@ func TestGetCartError(t *testing.T) {
 }
 
 func TestDeleteCartOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -87,8 +93,9 @@ func TestDeleteCartOk(t *testing.T) {
 	}
 }
 func TestDeleteCartError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -102,13 +109,14 @@ func TestDeleteCartError(t *testing.T) {
 }
 
 func TestAddItemOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPost, "/cart/{cart_id}/item")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
 	}
-	bodyBytes, _ := json.Marshal(viewmodels.AddItemToCartRequest{})
+	bodyBytes, _ := json.Marshal(viewmodels.AddItemToCartRequest{ID: "1", Quantity: 1})
 	req, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader(bodyBytes))
 	c.AddItem(r, req)
 
@@ -117,8 +125,9 @@ func TestAddItemOk(t *testing.T) {
 	}
 }
 func TestAddItemBadRequest(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPost, "/cart/{cart_id}/item")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -130,14 +139,37 @@ func TestAddItemBadRequest(t *testing.T) {
 		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
 	}
 }
+func TestAddItemViolations(t *testing.T) {
+	r := newRecorder(t, http.MethodPost, "/cart/{cart_id}/item")
+	c := controller.CartController{
+		Logger: logrus.New(),
+		Service: &mockService{
+			shouldFail: false,
+		},
+	}
+	req, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader([]byte(`{"quantity":"2","colour":"red"}`)))
+	c.AddItem(r, req)
+
+	if r.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	res := struct {
+		Error viewmodels.Error `json:"error"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if len(res.Error.Details) != 3 {
+		t.Fatalf("Every violation was expected: %+v", res.Error)
+	}
+}
 func TestAddItemError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPost, "/cart/{cart_id}/item")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
 	}
-	bodyBytes, _ := json.Marshal(viewmodels.AddItemToCartRequest{})
+	bodyBytes, _ := json.Marshal(viewmodels.AddItemToCartRequest{ID: "1", Quantity: 1})
 	req, _ := http.NewRequest(http.MethodPost, "", bytes.NewReader(bodyBytes))
 	c.AddItem(r, req)
 
@@ -147,13 +179,14 @@ func TestAddItemError(t *testing.T) {
 }
 
 func TestUpdateQuantityOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPut, "/cart/{cart_id}/item/{item_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
 	}
-	bodyBytes, _ := json.Marshal(viewmodels.ModifyItemQuantityRequest{})
+	bodyBytes, _ := json.Marshal(viewmodels.ModifyItemQuantityRequest{Quantity: 2})
 	req, _ := http.NewRequest(http.MethodPut, "", bytes.NewReader(bodyBytes))
 	c.UpdateQuantity(r, req)
 
@@ -162,8 +195,9 @@ func TestUpdateQuantityOk(t *testing.T) {
 	}
 }
 func TestUpdateQuantityBadRequest(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPut, "/cart/{cart_id}/item/{item_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -176,13 +210,14 @@ func TestUpdateQuantityBadRequest(t *testing.T) {
 	}
 }
 func TestUpdateQuantityError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodPut, "/cart/{cart_id}/item/{item_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
 	}
-	bodyBytes, _ := json.Marshal(viewmodels.ModifyItemQuantityRequest{})
+	bodyBytes, _ := json.Marshal(viewmodels.ModifyItemQuantityRequest{Quantity: 2})
 	req, _ := http.NewRequest(http.MethodPut, "", bytes.NewReader(bodyBytes))
 	c.UpdateQuantity(r, req)
 
@@ -192,8 +227,9 @@ func TestUpdateQuantityError(t *testing.T) {
 }
 
 func TestRemoveItemOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}/item/{item_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -206,8 +242,9 @@ func TestRemoveItemOk(t *testing.T) {
 	}
 }
 func TestRemoveItemError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}/item/{item_id}")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -221,8 +258,9 @@ func TestRemoveItemError(t *testing.T) {
 }
 
 func TestRemoveAllItemsOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}/item/all")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -235,8 +273,9 @@ func TestRemoveAllItemsOk(t *testing.T) {
 	}
 }
 func TestRemoveAllItemsError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodDelete, "/cart/{cart_id}/item/all")
 	c := controller.CartController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -253,6 +292,7 @@ func TestRemoveAllItemsError(t *testing.T) {
 
 type mockService struct {
 	shouldFail bool
+	err        error
 }
 
 func (ms *mockService) CreateCart(ctx context.Context) (models.Cart, error) {
@@ -263,12 +303,18 @@ func (ms *mockService) CreateCart(ctx context.Context) (models.Cart, error) {
 	return models.Cart{}, nil
 }
 func (ms *mockService) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
+	if ms.err != nil {
+		return models.Cart{}, ms.err
+	}
 	if ms.shouldFail {
 		return models.Cart{}, fmt.Errorf("Mock Service was asked to fail")
 	}
 
 	return models.Cart{}, nil
 }
+func (ms *mockService) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return ms.GetCart(ctx, cartID)
+}
 func (ms *mockService) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
 	if ms.shouldFail {
 		return []models.Item{}, fmt.Errorf("Mock Service was asked to fail")
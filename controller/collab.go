@@ -0,0 +1,195 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	collabWriteTimeout = time.Second * 10
+	collabPingInterval = time.Second * 30
+	//collabPongTimeout is how long a viewer may stay silent, pongs included, before the connection is given up
+	collabPongTimeout = time.Second * 60
+	//collabMaxMessageSize caps commands, a command is a few dozen bytes
+	collabMaxMessageSize = 4096
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+}
+
+type CollabController struct {
+	Service service.CartService
+	Broker  collab.Broker
+	Logger  *logrus.Logger
+}
+
+//SharedCart upgrades the connection to a WebSocket where several users edit the same cart.
+//Commands go through the CartService and the resulting cart is broadcasted to every viewer.
+func (c *CollabController) SharedCart(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	cartID := vars["cart_id"]
+	viewer := r.URL.Query().Get("viewer")
+	if viewer == "" {
+		viewer = uuid.New().String()
+	}
+
+	//we make sure the cart exists before upgrading, so the client gets a regular HTTP error
+	cart, err := c.Service.GetCart(r.Context(), cartID)
+	if err != nil {
+		respondWithError(c.Logger, w, r, err)
+		return
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		//Upgrade already replied to the client
+		return
+	}
+	defer conn.Close()
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	events, err := c.Broker.Subscribe(ctx, cartID)
+	if err != nil {
+		conn.WriteJSON(viewmodels.CollabErrorMessage(err))
+		return
+	}
+	viewers, err := c.Broker.Join(ctx, cartID, viewer)
+	if err != nil {
+		conn.WriteJSON(viewmodels.CollabErrorMessage(err))
+		return
+	}
+	defer func() {
+		//the request context is gone by now, leaving must still happen
+		leaveCtx := requestid.NewContext(context.Background(), requestid.FromContext(r.Context()))
+		leaveCtx = tenant.NewContext(leaveCtx, tenant.FromContext(r.Context()))
+		leaveCtx, leaveCancel := context.WithTimeout(leaveCtx, collabWriteTimeout)
+		defer leaveCancel()
+		if viewers, err := c.Broker.Leave(leaveCtx, cartID, viewer); err == nil {
+			c.Broker.Publish(leaveCtx, collab.Event{Type: collab.EventPresence, CartID: cartID, Viewers: viewers})
+		}
+	}()
+	c.Broker.Publish(ctx, collab.Event{Type: collab.EventPresence, CartID: cartID, Viewers: viewers})
+
+	//every write happens on the writer goroutine, gorilla/websocket allows a single writer
+	replies := make(chan viewmodels.CollabMessage, 1)
+	replies <- cartMessage(cart)
+	go c.writeLoop(ctx, cancel, conn, cartID, events, replies)
+
+	//a half-open connection never answers the pings, the deadline unblocks the read
+	conn.SetReadLimit(collabMaxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(collabPongTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(collabPongTimeout))
+	})
+	for {
+		_, payload, err := conn.ReadMessage()
+		if err != nil {
+			//the client went away or the connection broke
+			return
+		}
+		cmd := viewmodels.CollabCommand{}
+		if err := json.Unmarshal(payload, &cmd); err != nil {
+			select {
+			case replies <- viewmodels.CollabErrorMessage(viewmodels.StandardBadBodyRequest):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		cart, err := c.apply(ctx, cartID, cmd)
+		if err != nil {
+			select {
+			case replies <- viewmodels.CollabErrorMessage(err):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		c.Broker.Publish(ctx, collab.Event{Type: collab.EventCartUpdated, CartID: cartID, Cart: &cart})
+	}
+}
+
+func (c *CollabController) apply(ctx context.Context, cartID string, cmd viewmodels.CollabCommand) (models.Cart, error) {
+	switch cmd.Type {
+	case viewmodels.CollabCommandAdd:
+		return c.Service.AddItemToCart(ctx, cartID, cmd.ItemID, cmd.Quantity)
+	case viewmodels.CollabCommandUpdate:
+		return c.Service.ModifyItemInCart(ctx, cartID, cmd.ItemID, cmd.Quantity)
+	case viewmodels.CollabCommandRemove:
+		return c.Service.DeleteItemInCart(ctx, cartID, cmd.ItemID)
+	}
+	return models.Cart{}, viewmodels.StandardBadBodyRequest
+}
+
+//writeLoop writes every message to the viewer. The connection is closed when it stops, so the read loop stops too.
+func (c *CollabController) writeLoop(ctx context.Context, cancel context.CancelFunc, conn *websocket.Conn, cartID string, events <-chan collab.Event, replies <-chan viewmodels.CollabMessage) {
+	defer conn.Close()
+	defer cancel()
+	ping := time.NewTicker(collabPingInterval)
+	defer ping.Stop()
+
+	for {
+		var msg viewmodels.CollabMessage
+		select {
+		case <-ctx.Done():
+			conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(collabWriteTimeout))
+			return
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(collabWriteTimeout)); err != nil {
+				return
+			}
+			c.Broker.Refresh(ctx, cartID)
+			continue
+		case msg = <-replies:
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() == nil {
+					//the viewer fell behind and missed events, reconnecting gets the cart again
+					conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "missed updates, reconnect"), time.Now().Add(collabWriteTimeout))
+				}
+				return
+			}
+			msg = eventMessage(event)
+		}
+		conn.SetWriteDeadline(time.Now().Add(collabWriteTimeout))
+		if err := conn.WriteJSON(msg); err != nil {
+			return
+		}
+	}
+}
+
+func cartMessage(cart models.Cart) viewmodels.CollabMessage {
+	vmCart := viewmodels.CartModelToViewmodel(cart)
+	return viewmodels.CollabMessage{
+		Type: viewmodels.CollabMessageCart,
+		Cart: &vmCart,
+	}
+}
+
+func eventMessage(event collab.Event) viewmodels.CollabMessage {
+	if event.Type == collab.EventCartUpdated && event.Cart != nil {
+		return cartMessage(*event.Cart)
+	}
+	return viewmodels.CollabMessage{
+		Type:    viewmodels.CollabMessagePresence,
+		Viewers: event.Viewers,
+	}
+}
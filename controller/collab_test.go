@@ -0,0 +1,150 @@
+package controller_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+	"github.com/sirupsen/logrus"
+)
+
+func newCollabServer(shouldFail bool) *httptest.Server {
+	c := controller.CollabController{
+		Logger: logrus.New(),
+		Service: &mockService{
+			shouldFail: shouldFail,
+		},
+		Broker: collab.NewMemoryBroker(),
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/cart/{cart_id}/ws", c.SharedCart)
+	return httptest.NewServer(r)
+}
+
+func dialCollab(t *testing.T, srv *httptest.Server, viewer string) *websocket.Conn {
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/cart/someCart/ws?viewer=" + viewer
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	return conn
+}
+
+//readUntil discards messages until one of the given type arrives
+func readUntil(t *testing.T, conn *websocket.Conn, msgType string) viewmodels.CollabMessage {
+	conn.SetReadDeadline(time.Now().Add(time.Second * 2))
+	for {
+		msg := viewmodels.CollabMessage{}
+		if err := conn.ReadJSON(&msg); err != nil {
+			t.Fatalf("Expected a %s message: %s", msgType, err)
+		}
+		if msg.Type == msgType {
+			return msg
+		}
+	}
+}
+
+func TestSharedCartBroadcast(t *testing.T) {
+	srv := newCollabServer(false)
+	defer srv.Close()
+
+	alice := dialCollab(t, srv, "alice")
+	defer alice.Close()
+	readUntil(t, alice, viewmodels.CollabMessageCart)
+
+	bob := dialCollab(t, srv, "bob")
+	defer bob.Close()
+	readUntil(t, bob, viewmodels.CollabMessageCart)
+
+	presence := readUntil(t, alice, viewmodels.CollabMessagePresence)
+	for len(presence.Viewers) != 2 {
+		presence = readUntil(t, alice, viewmodels.CollabMessagePresence)
+	}
+
+	bob.WriteJSON(viewmodels.CollabCommand{Type: viewmodels.CollabCommandAdd, ItemID: "1", Quantity: 2})
+
+	if msg := readUntil(t, alice, viewmodels.CollabMessageCart); msg.Cart == nil {
+		t.Fatalf("Cart was expected in broadcast")
+	}
+}
+
+func TestSharedCartCommandError(t *testing.T) {
+	srv := newCollabServer(false)
+	defer srv.Close()
+
+	conn := dialCollab(t, srv, "alice")
+	defer conn.Close()
+
+	conn.WriteJSON(viewmodels.CollabCommand{Type: "unknown"})
+
+	msg := readUntil(t, conn, viewmodels.CollabMessageError)
+	if msg.Error == nil || msg.Error.Code != viewmodels.ErrCodeBadRequest {
+		t.Fatalf("Unexpected error message")
+	}
+}
+
+func TestSharedCartNotFound(t *testing.T) {
+	c := controller.CollabController{
+		Logger: logrus.New(),
+		Service: &mockService{
+			err: serviceErrors.ServiceError{Code: serviceErrors.CartNotFoundCode},
+		},
+		Broker: collab.NewMemoryBroker(),
+	}
+	r := mux.NewRouter()
+	r.HandleFunc("/cart/{cart_id}/ws", c.SharedCart)
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/cart/someCart/ws"
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatalf("Error was expected")
+	}
+	if res.StatusCode != http.StatusNotFound {
+		t.Fatalf("Unexpected Status Code: %d", res.StatusCode)
+	}
+}
+
+func TestSharedCartError(t *testing.T) {
+	srv := newCollabServer(true)
+	defer srv.Close()
+
+	url := "ws" + strings.TrimPrefix(srv.URL, "http") + "/cart/someCart/ws"
+	_, res, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatalf("Error was expected")
+	}
+	if res.StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Unexpected Status Code: %d", res.StatusCode)
+	}
+}
+
+func TestSharedCartTooLargeMessage(t *testing.T) {
+	srv := newCollabServer(false)
+	defer srv.Close()
+
+	conn := dialCollab(t, srv, "alice")
+	defer conn.Close()
+	readUntil(t, conn, viewmodels.CollabMessageCart)
+
+	conn.WriteMessage(websocket.TextMessage, []byte(strings.Repeat(" ", 8192)))
+
+	conn.SetReadDeadline(time.Now().Add(time.Second * 2))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseMessageTooBig) {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+			return
+		}
+	}
+}
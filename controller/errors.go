@@ -0,0 +1,50 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/sirupsen/logrus"
+)
+
+//logLevels are the levels errors are logged at by code, the ones missing are logged as errors.
+//Errors of the client are expected, the ones of the service or its dependencies need a look.
+var logLevels = map[string]logrus.Level{
+	serviceErrors.InternalCode:               logrus.ErrorLevel,
+	serviceErrors.BadRequestCode:             logrus.InfoLevel,
+	serviceErrors.CartNotFoundCode:           logrus.InfoLevel,
+	serviceErrors.ItemNotFoundCode:           logrus.InfoLevel,
+	serviceErrors.ItemNotFoundOnProviderCode: logrus.InfoLevel,
+	serviceErrors.ItemAlreadyInCartCode:      logrus.InfoLevel,
+	serviceErrors.ExternalApiErrorCode:       logrus.ErrorLevel,
+	serviceErrors.CacheErrorCode:             logrus.ErrorLevel,
+}
+
+//respondWithError logs err at the level of its code, cause included,
+//and answers with what clients may see of it
+func respondWithError(logger *logrus.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	code, cause := serviceErrors.InternalCode, err
+	sErr := &serviceErrors.ServiceError{}
+	vErr := &viewmodels.Error{}
+	switch {
+	case errors.As(err, sErr):
+		code, cause = sErr.Code, sErr.Cause
+	case errors.As(err, vErr):
+		code, cause = vErr.Code, nil
+	}
+
+	entry := logger.WithContext(r.Context()).WithField("code", code)
+	if cause != nil {
+		entry = entry.WithError(cause)
+	}
+	level, ok := logLevels[code]
+	if !ok {
+		level = logrus.ErrorLevel
+	}
+	entry.Log(level, "request_error")
+
+	viewmodels.RespondWithError(w, r, err)
+}
@@ -0,0 +1,56 @@
+package controller_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestErrorCauseLoggedNotLeaked(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	r := newRecorder(t, http.MethodGet, "/cart/{cart_id}")
+	c := controller.CartController{
+		Logger: logger,
+		Service: &mockService{
+			err: serviceErrors.Wrap(serviceErrors.CacheErrorCode, errors.New("dial tcp 10.0.0.7:6379: connection refused")),
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "", nil)
+	c.GetCart(r, mux.SetURLVars(req, map[string]string{"cart_id": "someCart"}))
+
+	if r.Result().StatusCode != http.StatusInternalServerError {
+		t.Fatalf("Unexpected Status Code")
+	}
+	if strings.Contains(r.Body.String(), "10.0.0.7") || !strings.Contains(r.Body.String(), `"retryable":true`) {
+		t.Fatalf("Unexpected body %s", r.Body.String())
+	}
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.ErrorLevel || entry.Data["code"] != serviceErrors.CacheErrorCode || !strings.Contains(entry.Data[logrus.ErrorKey].(error).Error(), "10.0.0.7") {
+		t.Fatalf("The cause was expected to be logged")
+	}
+}
+
+func TestErrorLoggedAtItsLevel(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	c := controller.CartController{
+		Logger: logger,
+		Service: &mockService{
+			err: serviceErrors.ServiceError{Code: serviceErrors.CartNotFoundCode},
+		},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "", nil)
+	c.GetCart(httptest.NewRecorder(), mux.SetURLVars(req, map[string]string{"cart_id": "someCart"}))
+
+	if entry := hook.LastEntry(); entry == nil || entry.Level != logrus.InfoLevel {
+		t.Fatalf("A missing cart was expected to be logged at info")
+	}
+}
@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"time"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
@@ -11,30 +12,70 @@ type HealthController struct {
 	Service health.Service
 }
 
-//Health is the handler for the health endpoint
+//Health is the handler for the health endpoint, it reports every dependency.
+//It answers 503 when the service is not ready.
 func (c *HealthController) Health(w http.ResponseWriter, r *http.Request) {
+	report := c.Service.Check(r.Context())
 
-	//using lower level pkg to do the logic
-	service, external, db, err := c.Service.HealthCheck()
-	if err != nil {
-		viewmodels.RespondWithError(w, viewmodels.StandardInternalServerError)
-		return
-	}
 	hr := viewmodels.HealthResponse{
-		Services: []viewmodels.Health{
-			{
-				Name:  "service",
-				Alive: service,
-			},
-			{
-				Name:  "external api",
-				Alive: external,
-			},
-			{
-				Name:  "Cache",
-				Alive: db,
-			},
-		},
+		Status:   report.Status,
+		Services: []viewmodels.Health{},
+	}
+	for _, result := range report.Results {
+		hr.Services = append(hr.Services, viewmodels.Health{
+			Name:          result.Name,
+			Alive:         result.Status != health.StatusDown,
+			Status:        result.Status,
+			Critical:      result.Critical,
+			LatencyMs:     float64(result.Latency) / float64(time.Millisecond),
+			LastError:     result.LastError,
+			LastErrorAt:   optionalTime(result.LastErrorAt),
+			LastSuccessAt: optionalTime(result.LastSuccessAt),
+		})
+	}
+
+	status := http.StatusOK
+	if !report.Ready() {
+		status = http.StatusServiceUnavailable
+	}
+	viewmodels.RespondWithData(w, status, hr)
+}
+
+//Ready is the handler for the readiness probe, it answers 503 when a critical dependency is down
+func (c *HealthController) Ready(w http.ResponseWriter, r *http.Request) {
+	c.Health(w, r)
+}
+
+//Live is the handler for the liveness probe, it answers as long as the process serves requests
+//and does not check dependencies, so a dependency going down does not get the service restarted
+func (c *HealthController) Live(w http.ResponseWriter, r *http.Request) {
+	viewmodels.RespondWithData(w, http.StatusOK, viewmodels.HealthResponse{
+		Status:   health.StatusUp,
+		Services: []viewmodels.Health{},
+	})
+}
+
+//History is the handler for the health history, the last status transitions of the dependencies
+func (c *HealthController) History(w http.ResponseWriter, r *http.Request) {
+	hr := viewmodels.HealthHistoryResponse{
+		Transitions: []viewmodels.HealthTransition{},
+	}
+	for _, t := range c.Service.History() {
+		hr.Transitions = append(hr.Transitions, viewmodels.HealthTransition{
+			Name:       t.Name,
+			From:       t.From,
+			To:         t.To,
+			At:         t.At,
+			DurationMs: t.Duration.Milliseconds(),
+			Error:      t.Error,
+		})
 	}
 	viewmodels.RespondWithData(w, http.StatusOK, hr)
 }
+
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
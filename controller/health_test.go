@@ -1,23 +1,21 @@
 package controller_test
 
 import (
-	"fmt"
+	"context"
+	"encoding/json"
 	"net/http"
-	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
 )
 
 func TestHealthOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/health")
 	c := controller.HealthController{
-		Service: &healthMock{
-			shouldServiceFail:  false,
-			shouldCacheFail:    false,
-			shouldExternalFail: false,
-			shouldReturnError:  false,
-		},
+		Service: &healthMock{},
 	}
 	req, _ := http.NewRequest(http.MethodGet, "", nil)
 	c.Health(r, req)
@@ -25,37 +23,116 @@ func TestHealthOk(t *testing.T) {
 	if r.Result().StatusCode != http.StatusOK {
 		t.Fatalf("Unexpected Status Code")
 	}
+	res := struct {
+		Data viewmodels.HealthResponse `json:"data"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if res.Data.Status != health.StatusUp || len(res.Data.Services) != 2 {
+		t.Fatalf("Unexpected health %+v", res.Data)
+	}
+	cache := res.Data.Services[0]
+	if !cache.Alive || cache.LatencyMs != 1.5 || cache.LastSuccessAt == nil || cache.LastErrorAt != nil {
+		t.Fatalf("Unexpected dependency %+v", cache)
+	}
 }
-func TestHealthError(t *testing.T) {
-	r := httptest.NewRecorder()
+
+func TestHealthDegraded(t *testing.T) {
+	r := newRecorder(t, http.MethodGet, "/health/ready")
 	c := controller.HealthController{
-		Service: &healthMock{
-			shouldServiceFail:  false,
-			shouldCacheFail:    false,
-			shouldExternalFail: false,
-			shouldReturnError:  true,
-		},
+		Service: &healthMock{shouldExternalFail: true},
 	}
 	req, _ := http.NewRequest(http.MethodGet, "", nil)
-	c.Health(r, req)
+	c.Ready(r, req)
 
-	if r.Result().StatusCode != http.StatusInternalServerError {
+	if r.Result().StatusCode != http.StatusOK {
 		t.Fatalf("Unexpected Status Code")
 	}
 }
 
+func TestHealthNotReady(t *testing.T) {
+	c := controller.HealthController{
+		Service: &healthMock{shouldCacheFail: true},
+	}
+	for template, handler := range map[string]http.HandlerFunc{"/health": c.Health, "/health/ready": c.Ready} {
+		r := newRecorder(t, http.MethodGet, template)
+		req, _ := http.NewRequest(http.MethodGet, "", nil)
+		handler(r, req)
+
+		if r.Result().StatusCode != http.StatusServiceUnavailable {
+			t.Fatalf("Unexpected Status Code")
+		}
+	}
+}
+
+func TestHealthLive(t *testing.T) {
+	r := newRecorder(t, http.MethodGet, "/health/live")
+	service := &healthMock{shouldCacheFail: true}
+	c := controller.HealthController{
+		Service: service,
+	}
+	req, _ := http.NewRequest(http.MethodGet, "", nil)
+	c.Live(r, req)
+
+	if r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code")
+	}
+	if service.checked {
+		t.Fatalf("Liveness was not expected to check dependencies")
+	}
+}
+
+func TestHealthHistory(t *testing.T) {
+	r := newRecorder(t, http.MethodGet, "/health/history")
+	c := controller.HealthController{
+		Service: &healthMock{},
+	}
+	req, _ := http.NewRequest(http.MethodGet, "", nil)
+	c.History(r, req)
+
+	if r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code")
+	}
+	res := struct {
+		Data viewmodels.HealthHistoryResponse `json:"data"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if len(res.Data.Transitions) != 1 || res.Data.Transitions[0].DurationMs != 90000 || res.Data.Transitions[0].To != health.StatusUp {
+		t.Fatalf("Unexpected history %+v", res.Data)
+	}
+}
+
 //******** Health Service Mock
 
 type healthMock struct {
-	shouldServiceFail  bool
 	shouldCacheFail    bool
 	shouldExternalFail bool
-	shouldReturnError  bool
+	checked            bool
+}
+
+func (hm *healthMock) Check(ctx context.Context) health.Report {
+	hm.checked = true
+	report := health.Report{
+		Status: health.StatusUp,
+		Results: []health.Result{
+			{Name: health.CheckCache, Critical: true, Status: health.StatusUp, Latency: 1500 * time.Microsecond, LastSuccessAt: time.Now()},
+			{Name: health.CheckExternalAPI, Status: health.StatusUp, LastSuccessAt: time.Now()},
+		},
+	}
+	if hm.shouldExternalFail {
+		report.Status = health.StatusDegraded
+		report.Results[1].Status = health.StatusDown
+		report.Results[1].LastError = "Health Mock was asked to fail"
+	}
+	if hm.shouldCacheFail {
+		report.Status = health.StatusDown
+		report.Results[0].Status = health.StatusDown
+		report.Results[0].LastError = "Health Mock was asked to fail"
+	}
+	return report
 }
 
-func (hm *healthMock) HealthCheck() (service bool, externalAPI bool, cache bool, err error) {
-	if hm.shouldReturnError {
-		return hm.shouldServiceFail, hm.shouldExternalFail, hm.shouldCacheFail, fmt.Errorf("Health Mock was asked to fail")
+func (hm *healthMock) History() []health.Transition {
+	return []health.Transition{
+		{Name: health.CheckCache, From: health.StatusDown, To: health.StatusUp, At: time.Now(), Duration: 90 * time.Second},
 	}
-	return hm.shouldServiceFail, hm.shouldExternalFail, hm.shouldCacheFail, nil
 }
@@ -6,10 +6,12 @@ import (
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
 	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
 )
 
 type ItemController struct {
 	Service service.CartService
+	Logger  *logrus.Logger
 }
 
 //GetAllItems returns all items from the external API
@@ -17,7 +19,7 @@ func (c *ItemController) GetAllItems(w http.ResponseWriter, r *http.Request) {
 
 	items, err := c.Service.GetAvailableItems(r.Context())
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 	vmItems := []viewmodels.Item{}
@@ -39,7 +41,7 @@ func (c *ItemController) GetItem(w http.ResponseWriter, r *http.Request) {
 	itemID := vars["item_id"]
 	item, err := c.Service.GetItem(r.Context(), itemID)
 	if err != nil {
-		viewmodels.RespondWithError(w, err)
+		respondWithError(c.Logger, w, r, err)
 		return
 	}
 	vmItem := viewmodels.Item{
@@ -2,15 +2,16 @@ package controller_test
 
 import (
 	"net/http"
-	"net/http/httptest"
 	"testing"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
+	"github.com/sirupsen/logrus"
 )
 
 func TestGetAllItemsOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/items/available")
 	c := controller.ItemController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -23,8 +24,9 @@ func TestGetAllItemsOk(t *testing.T) {
 	}
 }
 func TestGetAllItemsError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/items/available")
 	c := controller.ItemController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -38,8 +40,9 @@ func TestGetAllItemsError(t *testing.T) {
 }
 
 func TestGetItemOk(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/items/{item_id}")
 	c := controller.ItemController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: false,
 		},
@@ -52,8 +55,9 @@ func TestGetItemOk(t *testing.T) {
 	}
 }
 func TestGetItemError(t *testing.T) {
-	r := httptest.NewRecorder()
+	r := newRecorder(t, http.MethodGet, "/items/{item_id}")
 	c := controller.ItemController{
+		Logger: logrus.New(),
 		Service: &mockService{
 			shouldFail: true,
 		},
@@ -0,0 +1,38 @@
+package controller_test
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/oas"
+)
+
+var specValidator, specErr = oas.NewValidator()
+
+//specRecorder is a ResponseRecorder keeping a copy of the body, tests are free to read Body
+type specRecorder struct {
+	*httptest.ResponseRecorder
+	written bytes.Buffer
+}
+
+func (s *specRecorder) Write(b []byte) (int, error) {
+	s.written.Write(b)
+	return s.ResponseRecorder.Write(b)
+}
+
+//newRecorder records the response of the handler of the route with method and template.
+//Once the test is done the response is checked against the OpenAPI spec, and the test fails when it doesn't match.
+func newRecorder(t *testing.T, method, template string) *specRecorder {
+	if specErr != nil {
+		t.Fatalf("Unexpected error loading the spec: %v", specErr)
+	}
+	r := &specRecorder{ResponseRecorder: httptest.NewRecorder()}
+	t.Cleanup(func() {
+		req := httptest.NewRequest(method, template, nil)
+		if err := specValidator.ValidateResponse(req, template, nil, r.Code, r.Header(), r.written.Bytes()); err != nil {
+			t.Errorf("Unexpected response not matching the spec: %v", err)
+		}
+	})
+	return r
+}
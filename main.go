@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -10,46 +11,175 @@ import (
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tracing"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+	grpcTransport "github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc"
 
-	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/grpc"
 )
 
 func main() {
 	logrus.SetFormatter(&logrus.JSONFormatter{})
 	log := logrus.New()
+	log.AddHook(requestid.Hook{})
+	log.AddHook(tenant.Hook{})
 
-	redisClient := redis.NewClient(&redis.Options{
-		Addr:     config.GetEnvString(config.RedisServerKey, ""),
-		Password: config.GetEnvString(config.RedisPasswordKey, ""),
+	redisClient, err := cache.NewRedisClient(cache.RedisConfig{
+		Mode:                  config.GetEnvString(config.RedisModeKey, cache.ModeStandalone),
+		Addrs:                 config.GetEnvList(config.RedisServerKey, []string{}),
+		MasterName:            config.GetEnvString(config.RedisSentinelMasterKey, ""),
+		Username:              config.GetEnvString(config.RedisUsernameKey, ""),
+		Password:              config.GetEnvString(config.RedisPasswordKey, ""),
+		SentinelPassword:      config.GetEnvString(config.RedisSentinelPasswordKey, ""),
+		DB:                    config.GetEnvInt(config.RedisDBKey, 0),
+		TLS:                   config.GetEnvBool(config.RedisTLSKey, false),
+		TLSCAFile:             config.GetEnvString(config.RedisTLSCAFileKey, ""),
+		TLSInsecureSkipVerify: config.GetEnvBool(config.RedisTLSInsecureSkipVerifyKey, false),
+		PoolSize:              config.GetEnvInt(config.RedisPoolSizeKey, 0),
+		DialTimeout:           config.GetEnvDuration(config.RedisDialTimeoutKey, 0),
+		ReadTimeout:           config.GetEnvDuration(config.RedisReadTimeoutKey, 0),
+		WriteTimeout:          config.GetEnvDuration(config.RedisWriteTimeoutKey, 0),
 	})
+	if err != nil {
+		log.WithError(err).Fatal("cache_error")
+	}
+
+	tracerProvider, shutdownTracing, err := tracing.NewTracerProvider(context.Background(), config.GetTracingExporter(), config.GetVersion())
+	if err != nil {
+		log.WithError(err).Fatal("tracing_error")
+	}
+
+	cacheCodec, err := cache.NewCodec(config.GetCacheCodec())
+	if err != nil {
+		log.WithError(err).Fatal("cache_error")
+	}
+
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
 
-	cacheClient := cache.NewRedisCache(
+	cacheOptions := []cache.Option{
+		cache.WithKeyPrefix(config.GetCacheKeyPrefix()),
+		cache.WithCodec(cacheCodec),
+		cache.WithLogPolicy(cache.LogPolicy{
+			Level:         config.GetCacheLogLevel(),
+			Disabled:      config.GetCacheLogDisabled(),
+			Values:        config.GetCacheLogValues(),
+			MaxValueBytes: config.GetCacheLogMaxValueBytes(),
+			RedactFields:  config.GetCacheLogRedactFields(),
+		}),
+	}
+	if config.GetCacheLegacyKeys() {
+		cacheOptions = append(cacheOptions, cache.WithLegacyKeys())
+	}
+	var cacheStore cache.Cache = cache.NewRedisCache(
 		log.WithField("owner", "cache").Logger,
 		0,
 		redisClient,
+		cacheOptions...,
 	)
+	keyring, err := cacheKeyring()
+	if err != nil {
+		log.WithError(err).Fatal("cache_error")
+	}
+	if keyring != nil {
+		var encryptionOptions []cache.EncryptionOption
+		if config.GetCacheEncryptionReadPlaintext() {
+			encryptionOptions = append(encryptionOptions, cache.WithPlaintextReads())
+		}
+		cacheStore = cache.NewEncryptedCache(cacheStore, keyring, encryptionOptions...)
+	}
 
-	itemsExternalService := item.NewExternalService(log.WithField("owner", "external service").Logger, &http.Client{
-		Timeout: time.Second * 10,
-	})
+	//"service reencrypt-cache" rotates the cached values to the primary key and exits, it can run next to the service
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt-cache" {
+		if keyring == nil {
+			log.Fatal("cache encryption is not enabled")
+		}
+		rotated, err := cache.Reencrypt(context.Background(), cacheStore)
+		if err != nil {
+			log.WithError(err).WithField("rotated", rotated).Fatal("reencrypt_error")
+		}
+		log.WithField("rotated", rotated).WithField("key_id", keyring.Primary()).Info("Cache re-encrypted")
+		return
+	}
+
+	cacheClient := cache.NewInstrumentedCache(
+		cache.NewTracedCache(cacheStore, tracerProvider),
+		metricsRegistry,
+	)
+
+	itemsExternalService := item.NewInstrumentedService(
+		item.NewExternalService(log.WithField("owner", "external service").Logger, item.NewTracedClient(&http.Client{
+			Timeout: time.Second * 10,
+		}, tracerProvider)),
+		metricsRegistry,
+	)
+
+	var carts repository.CartRepository
+	switch storage := config.GetCartStorage(); storage {
+	case repository.BackendRedis:
+		carts = repository.NewRedisRepository(cacheClient)
+	case repository.BackendSQLite:
+		carts, err = repository.OpenSQLite(context.Background(), log.WithField("owner", "repository").Logger, config.GetSQLitePath())
+		if err != nil {
+			log.WithError(err).Fatal("repository_error")
+		}
+	default:
+		log.WithField("storage", storage).Fatal("unknown cart storage")
+	}
 
-	svc := service.NewCartService(
-		config.GetVersion(),
-		cacheClient,
-		itemsExternalService,
+	svc := service.NewTracedCartService(
+		service.NewCartService(
+			config.GetVersion(),
+			carts,
+			itemsExternalService,
+		),
+		tracerProvider,
 	)
 
+	healthChecks := health.NewRegistry()
+	healthChecks.MustRegister(
+		health.Check{Name: health.CheckCache, Critical: true, Run: health.AliveCheck(cacheClient.Alive)},
+		health.Check{Name: health.CheckExternalAPI, Run: itemsExternalService.Health},
+	)
+	//the redis storage is the cache, already checked
+	if config.GetCartStorage() != repository.BackendRedis {
+		healthChecks.MustRegister(health.Check{Name: health.CheckCartStorage, Critical: true, Run: health.AliveCheck(carts.Alive)})
+	}
+
 	hsvc := health.NewService(
-		cacheClient,
-		itemsExternalService,
+		healthChecks,
+		health.WithTimeout(config.GetHealthCheckTimeout()),
+		health.WithDegradedLatency(config.GetHealthDegradedLatency()),
+		health.WithCacheTTL(config.GetHealthCacheTTL()),
+		health.WithHistorySize(config.GetHealthHistorySize()),
+		health.WithLogger(log.WithField("owner", "health").Logger),
+	)
+	monitorCtx, stopMonitor := context.WithCancel(context.Background())
+	defer stopMonitor()
+	if interval := config.GetHealthMonitorInterval(); interval > 0 {
+		go health.Monitor(monitorCtx, hsvc, interval)
+	}
+
+	broker := collab.NewRedisBroker(
+		log.WithField("owner", "collab").Logger,
+		redisClient,
+		config.GetCacheKeyPrefix(),
 	)
 
-	httpTransportRouter := transport.NewHTTPRouter(svc, hsvc)
+	httpTransportRouter := transport.NewHTTPRouter(log.WithField("owner", "http").Logger, svc, hsvc, broker, metricsRegistry, tracerProvider)
 
 	srv := &http.Server{
 		Addr: fmt.Sprintf("0.0.0.0:%s", config.GetPort()),
@@ -74,6 +204,27 @@ func main() {
 		}
 	}()
 
+	grpcServer := grpcTransport.NewGRPCServer(svc, grpc.UnaryInterceptor(grpcTransport.TenantInterceptor(tenant.Resolver{
+		Allowed: config.GetTenants(),
+	})))
+	log.WithField(
+		"transport", "grpc").
+		WithField(
+			"port", config.GetGRPCPort()).
+		Log(logrus.InfoLevel, "Transport Start")
+	go func() {
+		lis, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%s", config.GetGRPCPort()))
+		if err == nil {
+			err = grpcServer.Serve(lis)
+		}
+		if err != nil {
+			log.WithField(
+				"transport", "grpc").
+				WithError(err).
+				Log(logrus.ErrorLevel, "Transport Stopped")
+		}
+	}()
+
 	c := make(chan os.Signal, 1)
 	// We'll accept graceful shutdowns when quit via SIGINT (Ctrl+C)
 	// SIGKILL, SIGQUIT or SIGTERM (Ctrl+/) will not be caught.
@@ -86,10 +237,39 @@ func main() {
 	defer cancel()
 	// Doesn't block if no connections, but will otherwise wait
 	// until the timeout deadline.
+	stopMonitor()
 	srv.Shutdown(ctx)
+	//open streams would keep GracefulStop waiting forever, they are cut when the deadline passes
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		log.WithField(
+			"transport", "grpc").
+			Log(logrus.WarnLevel, "Transport Stopped Forcefully")
+		grpcServer.Stop()
+	}
+	if err := shutdownTracing(ctx); err != nil {
+		log.WithError(err).Error("tracing_error")
+	}
 	// Optionally, you could run srv.Shutdown in a goroutine and block on
 	// <-ctx.Done() if your application should wait for other services
 	// to finalize based on context cancellation.
 	log.Log(logrus.InfoLevel, "Service gracefully shutted down")
 	os.Exit(0)
 }
+
+//cacheKeyring loads the cache encryption keys, nil when encryption is disabled
+func cacheKeyring() (*cache.Keyring, error) {
+	if path := config.GetCacheEncryptionKeysFile(); path != "" {
+		return cache.LoadKeyring(path)
+	}
+	if keys := config.GetCacheEncryptionKeys(); keys != "" {
+		return cache.ParseKeyring(keys)
+	}
+	return nil, nil
+}
@@ -0,0 +1,38 @@
+package oas
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+)
+
+//SpecContentType is the media type the OpenAPI document is served with
+const SpecContentType = "application/yaml"
+
+//Spec is the OpenAPI document of the API
+//
+//go:embed oas.yml
+var Spec []byte
+
+//swaggerUI is the swagger-ui distribution, its initializer loads the spec from /openapi.yml
+//
+//go:embed swagger-ui
+var swaggerUI embed.FS
+
+//SpecHandler serves the OpenAPI document
+func SpecHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", SpecContentType)
+		w.Write(Spec)
+	})
+}
+
+//UIHandler serves swagger-ui, index.html is the root
+func UIHandler() http.Handler {
+	ui, err := fs.Sub(swaggerUI, "swagger-ui")
+	if err != nil {
+		//the directory is embedded, it can't be missing
+		panic(err)
+	}
+	return http.FileServer(http.FS(ui))
+}
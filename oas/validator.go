@@ -0,0 +1,103 @@
+package oas
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+)
+
+//ErrNotDocumented is the error of routes the spec does not document, like /metrics
+var ErrNotDocumented = errors.New("the operation is not documented")
+
+//pathVariablePattern matches gorilla path variables with a pattern, {item_id:[0-9]+} is {item_id} in the spec
+var pathVariablePattern = regexp.MustCompile(`\{([^}:]+):[^}]+\}`)
+
+//PathOf gives the path of the spec documenting a gorilla route template.
+//The spec is served under /v1, unversioned aliases are documented by the same path.
+func PathOf(template string) string {
+	return pathVariablePattern.ReplaceAllString(strings.TrimPrefix(template, "/v1"), "{$1}")
+}
+
+//Validator checks requests and responses against the spec
+type Validator struct {
+	doc *openapi3.T
+}
+
+//NewValidator gives a Validator of the embedded spec
+func NewValidator() (*Validator, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return &Validator{doc: doc}, nil
+}
+
+//route finds the operation of a gorilla route template
+func (v *Validator) route(method, template string) (*routers.Route, error) {
+	path := PathOf(template)
+	item := v.doc.Paths.Find(path)
+	if item == nil {
+		return nil, ErrNotDocumented
+	}
+	operation := item.GetOperation(method)
+	if operation == nil {
+		return nil, ErrNotDocumented
+	}
+	return &routers.Route{
+		Spec:      v.doc,
+		Path:      path,
+		PathItem:  item,
+		Method:    method,
+		Operation: operation,
+	}, nil
+}
+
+//ValidateRequest checks r, matched by the gorilla route template with vars, against the spec.
+//Every broken rule is reported, the errors are openapi3filter errors in an openapi3.MultiError.
+//The body of r is read and put back.
+func (v *Validator) ValidateRequest(r *http.Request, template string, vars map[string]string) error {
+	route, err := v.route(r.Method, template)
+	if err != nil {
+		return err
+	}
+	return openapi3filter.ValidateRequest(r.Context(), &openapi3filter.RequestValidationInput{
+		Request:    r,
+		PathParams: vars,
+		Route:      route,
+		Options:    &openapi3filter.Options{MultiError: true},
+	})
+}
+
+//ValidateResponse checks the response to r against the spec, statuses the operation doesn't document are errors
+func (v *Validator) ValidateResponse(r *http.Request, template string, vars map[string]string, status int, header http.Header, body []byte) error {
+	route, err := v.route(r.Method, template)
+	if err != nil {
+		return err
+	}
+	options := &openapi3filter.Options{MultiError: true, IncludeResponseStatus: true}
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: vars,
+			Route:      route,
+			Options:    options,
+		},
+		Status:  status,
+		Header:  header,
+		Options: options,
+	}
+	if err := openapi3filter.ValidateResponse(r.Context(), input.SetBodyBytes(body)); err != nil {
+		return fmt.Errorf("%s %s answered %d: %w", r.Method, route.Path, status, err)
+	}
+	return nil
+}
@@ -2,84 +2,288 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"strings"
 	"time"
 
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
 )
 
+//ErrNotFound is returned by Get and Del when the key does not exist
+var ErrNotFound = redis.Nil
+
+//Cache keys are scoped to the tenant of the context, tenants can't reach each other's keys
 type Cache interface {
-	Set(key string, value interface{}) error
-	Get(key string, here interface{}) error
-	Del(key string) error
-	Alive() bool
+	Set(ctx context.Context, key string, value interface{}) error
+	Get(ctx context.Context, key string, here interface{}) error
+	Del(ctx context.Context, key string) error
+	Alive(ctx context.Context) bool
 }
 
 type redisCache struct {
-	client *redis.Client
-	ttl    time.Duration
-	logger *logrus.Logger
+	client     redis.UniversalClient
+	ttl        time.Duration
+	logger     *logrus.Logger
+	logPolicy  LogPolicy
+	keyPrefix  string
+	codec      Codec
+	legacyKeys bool
+}
+
+//Option customizes the redis cache
+type Option func(*redisCache)
+
+//WithLogPolicy replaces the DefaultLogPolicy
+func WithLogPolicy(policy LogPolicy) Option {
+	return func(c *redisCache) {
+		c.logPolicy = policy
+	}
+}
+
+//WithCodec replaces the JSON codec values are written with.
+//Values written with any other codec still decode.
+func WithCodec(codec Codec) Option {
+	return func(c *redisCache) {
+		c.codec = codec
+	}
+}
+
+//WithKeyPrefix puts every key under prefix, so the cache can share its Redis with other data
+func WithKeyPrefix(prefix string) Option {
+	return func(c *redisCache) {
+		c.keyPrefix = prefix
+	}
+}
+
+//WithLegacyKeys reads the keys of the default tenant still stored as they were before keys were namespaced,
+//the bare "cart1". Writing or deleting one of them drops the bare key, so carts move as they change.
+func WithLegacyKeys() Option {
+	return func(c *redisCache) {
+		c.legacyKeys = true
+	}
+}
+
+func NewRedisCache(logger *logrus.Logger, ttl time.Duration, client redis.UniversalClient, opts ...Option) Cache {
+	c := &redisCache{
+		client:    client,
+		ttl:       ttl,
+		logger:    logger,
+		logPolicy: DefaultLogPolicy(),
+		codec:     jsonCodec{},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
-func NewRedisCache(logger *logrus.Logger, ttl time.Duration, client *redis.Client) Cache {
-	return &redisCache{
-		client: client,
-		ttl:    ttl,
-		logger: logger,
+//key namespaces a key by prefix and by the tenant of the context: "cart1" of tenant shop-a is "<prefix>:shop-a:cart1".
+//Tenants can't contain ':', so no key of a tenant can land in the namespace of another.
+func (c *redisCache) key(ctx context.Context, key string) string {
+	key = tenant.FromContext(ctx) + ":" + key
+	if c.keyPrefix != "" {
+		key = c.keyPrefix + ":" + key
 	}
+	return key
 }
 
-func (c *redisCache) Set(key string, value interface{}) error {
-	b, err := json.Marshal(value)
+//legacy tells whether the bare key is read too, only the default tenant existed before keys were namespaced
+func (c *redisCache) legacy(ctx context.Context) bool {
+	return c.legacyKeys && tenant.FromContext(ctx) == tenant.Default
+}
+
+//logOperation logs an operation following the log policy
+func (c *redisCache) logOperation(logger *logrus.Entry, operation string, fields logrus.Fields, msg string) {
+	if !c.logPolicy.enabled(operation) {
+		return
+	}
+	logger.WithField("operation", operation).WithFields(fields).Log(c.logPolicy.Level, msg)
+}
+
+func (c *redisCache) Set(ctx context.Context, key string, value interface{}) error {
+	logger := c.logger.WithContext(ctx)
+	b, err := encode(c.codec, value)
 	if err != nil {
-		c.logger.WithError(err).Error("cache_error")
+		logger.WithError(err).Error("cache_error")
 		return err
 	}
-	c.logger.WithField("key", key).WithField("value", value).Log(logrus.InfoLevel, "Saving Value to Key")
-	err = c.client.Set(context.Background(), key, string(b), c.ttl).Err()
+	//hashing or redacting the value is not free, skip it when the entry would be dropped
+	if c.logPolicy.enabled(OperationSet) && c.logger.IsLevelEnabled(c.logPolicy.Level) {
+		c.logOperation(logger.WithField("key", key), OperationSet, c.logPolicy.valueFields(value, b), "Saving Value to Key")
+	}
+	if c.legacy(ctx) {
+		_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, c.key(ctx, key), string(b), c.ttl)
+			pipe.Del(ctx, key)
+			return nil
+		})
+	} else {
+		err = c.client.Set(ctx, c.key(ctx, key), string(b), c.ttl).Err()
+	}
 	if err != nil {
-		c.logger.WithError(err).Error("cache_error")
+		logger.WithError(err).Error("cache_error")
 		return err
 	}
 	return nil
 }
 
-func (c *redisCache) Get(key string, here interface{}) error {
-	c.logger.WithField("key", key).Log(logrus.InfoLevel, "Retrieving Key")
-	val, err := c.client.Get(context.Background(), key).Result()
+func (c *redisCache) Get(ctx context.Context, key string, here interface{}) error {
+	logger := c.logger.WithContext(ctx)
+	c.logOperation(logger, OperationGet, logrus.Fields{"key": key}, "Retrieving Key")
+	val, err := c.client.Get(ctx, c.key(ctx, key)).Result()
+	if errors.Is(err, redis.Nil) && c.legacy(ctx) {
+		val, err = c.client.Get(ctx, key).Result()
+	}
 	if err != nil {
-		c.logger.WithError(err).Error("cache_error")
+		logger.WithError(err).Error("cache_error")
 		return err
 	}
-	err = json.Unmarshal([]byte(val), here)
+	err = decode([]byte(val), here)
 	if err != nil {
-		c.logger.WithError(err).Error("cache_error")
+		logger.WithError(err).Error("cache_error")
 		return err
 	}
 	return nil
 }
 
-func (c *redisCache) Del(key string) error {
-	c.logger.WithField("key", key).Log(logrus.InfoLevel, "Deleting Key")
-	numErased, err := c.client.Del(context.Background(), key).Result()
+func (c *redisCache) Del(ctx context.Context, key string) error {
+	logger := c.logger.WithContext(ctx)
+	c.logOperation(logger, OperationDel, logrus.Fields{"key": key}, "Deleting Key")
+	var numErased int64
+	var err error
+	if c.legacy(ctx) {
+		var erased, legacyErased *redis.IntCmd
+		_, err = c.client.Pipelined(ctx, func(pipe redis.Pipeliner) error {
+			erased = pipe.Del(ctx, c.key(ctx, key))
+			legacyErased = pipe.Del(ctx, key)
+			return nil
+		})
+		if err == nil {
+			numErased = erased.Val() + legacyErased.Val()
+		}
+	} else {
+		numErased, err = c.client.Del(ctx, c.key(ctx, key)).Result()
+	}
 	if err != nil {
-		c.logger.WithError(err).Error("cache_error")
+		logger.WithError(err).Error("cache_error")
 		return err
 	}
 	if numErased == 0 {
-		c.logger.Error("cache key not found")
+		logger.Error("cache key not found")
 		return redis.Nil
 	}
 
 	return nil
 }
 
-func (c *redisCache) Alive() bool {
-	c.logger.Log(logrus.InfoLevel, "Pinging Redis")
-	if c.client.Ping(context.Background()).Err() != nil {
-		c.logger.Error("cache not connected")
+func (c *redisCache) Alive(ctx context.Context) bool {
+	logger := c.logger.WithContext(ctx)
+	c.logOperation(logger, OperationPing, nil, "Pinging Redis")
+	if c.client.Ping(ctx).Err() != nil {
+		logger.Error("cache not connected")
 		return false
 	}
 	return true
 }
+
+//scan calls fn with every key of the cache, and a context carrying its tenant.
+//With legacy keys, the bare keys are given too, as keys of the default tenant with legacy set.
+func (c *redisCache) scan(ctx context.Context, fn func(ctx context.Context, key string, legacy bool) error) error {
+	match, prefix := "*", ""
+	if c.keyPrefix != "" {
+		prefix = c.keyPrefix + ":"
+		//bare keys are outside the prefix
+		if !c.legacyKeys {
+			match = prefix + "*"
+		}
+	}
+	scan := func(ctx context.Context, client redis.Cmdable) error {
+		iter := client.Scan(ctx, 0, match, 100).Iterator()
+		for iter.Next(ctx) {
+			val := iter.Val()
+			if c.legacyKeys && !strings.Contains(val, ":") {
+				if err := fn(tenant.NewContext(ctx, tenant.Default), val, true); err != nil {
+					return err
+				}
+				continue
+			}
+			if !strings.HasPrefix(val, prefix) {
+				continue
+			}
+			name, key, ok := strings.Cut(strings.TrimPrefix(val, prefix), ":")
+			//not written by the cache, like the collab presence hashes
+			if !ok || !tenant.Valid(name) {
+				continue
+			}
+			if err := fn(tenant.NewContext(ctx, name), key, false); err != nil {
+				return err
+			}
+		}
+		return iter.Err()
+	}
+	//keys are spread across the masters of a cluster
+	if cluster, ok := c.client.(*redis.ClusterClient); ok {
+		return cluster.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+			return scan(ctx, master)
+		})
+	}
+	return scan(ctx, c.client)
+}
+
+//update replaces the value at key with the one fn gives, nil leaves it as is.
+//A legacy key is moved to its namespaced key with the value fn gives, or as it is with nil,
+//unless the namespaced key exists, which is read instead of it.
+//Nothing is written when the key is gone or changes in the meantime.
+func (c *redisCache) update(ctx context.Context, key string, legacy bool, fn func(decode func(here interface{}) error) (interface{}, error)) (bool, error) {
+	from, to := c.key(ctx, key), c.key(ctx, key)
+	if legacy {
+		from = key
+	}
+	written := false
+	err := c.client.Watch(ctx, func(tx *redis.Tx) error {
+		if legacy {
+			exists, err := tx.Exists(ctx, to).Result()
+			if err != nil || exists > 0 {
+				return err
+			}
+		}
+		val, err := tx.Get(ctx, from).Result()
+		if err != nil {
+			return err
+		}
+		value, err := fn(func(here interface{}) error { return decode([]byte(val), here) })
+		if err != nil || (value == nil && !legacy) {
+			return err
+		}
+		b := []byte(val)
+		if value != nil {
+			if b, err = encode(c.codec, value); err != nil {
+				return err
+			}
+		}
+		ttl, err := tx.PTTL(ctx, from).Result()
+		if err != nil {
+			return err
+		}
+		//keep the expiry the value had
+		if ttl < 0 {
+			ttl = 0
+		}
+		_, err = tx.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, to, string(b), ttl)
+			if legacy {
+				pipe.Del(ctx, from)
+			}
+			return nil
+		})
+		written = err == nil
+		return err
+	}, from, to)
+	if errors.Is(err, redis.Nil) || errors.Is(err, redis.TxFailedErr) {
+		return false, nil
+	}
+	return written, err
+}
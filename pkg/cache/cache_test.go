@@ -1,6 +1,7 @@
 package cache_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"testing"
@@ -16,10 +17,10 @@ var testLogger = logrus.New()
 func TestSetOK(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	b, _ := json.Marshal("test")
-	mock.ExpectSet("testKey", string(b), 0).SetVal("test")
+	mock.ExpectSet("default:testKey", "\x01"+string(b), 0).SetVal("test")
 	c := cache.NewRedisCache(testLogger, 0, db)
 
-	if c.Set("testKey", "test") != nil {
+	if c.Set(context.TODO(), "testKey", "test") != nil {
 		t.Fatalf("Error was not expected")
 	}
 }
@@ -28,7 +29,7 @@ func TestSetUnmarshallError(t *testing.T) {
 	db, _ := redismock.NewClientMock()
 	c := cache.NewRedisCache(testLogger, 0, db)
 
-	if c.Set("testKey", make(chan int)) == nil {
+	if c.Set(context.TODO(), "testKey", make(chan int)) == nil {
 		t.Fatalf("Error was expected")
 	}
 }
@@ -36,10 +37,10 @@ func TestSetUnmarshallError(t *testing.T) {
 func TestSetCacheError(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	b, _ := json.Marshal("test")
-	mock.ExpectSet("testKey", string(b), 0).SetErr(fmt.Errorf("mocked error"))
+	mock.ExpectSet("default:testKey", "\x01"+string(b), 0).SetErr(fmt.Errorf("mocked error"))
 	c := cache.NewRedisCache(testLogger, 0, db)
 
-	if c.Set("testKey", "test") == nil {
+	if c.Set(context.TODO(), "testKey", "test") == nil {
 		t.Fatalf("Error was expected")
 	}
 }
@@ -47,10 +48,10 @@ func TestSetCacheError(t *testing.T) {
 func TestGetOK(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	b, _ := json.Marshal("test")
-	mock.ExpectGet("testKey").SetVal(string(b))
+	mock.ExpectGet("default:testKey").SetVal(string(b))
 	c := cache.NewRedisCache(testLogger, 0, db)
 	str := ""
-	if c.Get("testKey", &str) != nil {
+	if c.Get(context.TODO(), "testKey", &str) != nil {
 		t.Fatalf("Error was not expected")
 	}
 	if str != "test" {
@@ -60,10 +61,10 @@ func TestGetOK(t *testing.T) {
 
 func TestGetCacheError(t *testing.T) {
 	db, mock := redismock.NewClientMock()
-	mock.ExpectGet("testKey").SetErr(fmt.Errorf("cache Error"))
+	mock.ExpectGet("default:testKey").SetErr(fmt.Errorf("cache Error"))
 	c := cache.NewRedisCache(testLogger, 0, db)
 	str := ""
-	if c.Get("testKey", &str) == nil {
+	if c.Get(context.TODO(), "testKey", &str) == nil {
 		t.Fatalf("Error was expected")
 	}
 }
@@ -71,37 +72,37 @@ func TestGetCacheError(t *testing.T) {
 func TestGetUnmarshalFailure(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	b, _ := json.Marshal("test")
-	mock.ExpectGet("testKey").SetVal(string(b))
+	mock.ExpectGet("default:testKey").SetVal(string(b))
 	c := cache.NewRedisCache(testLogger, 0, db)
 	hereImpossible := make(chan int)
-	if c.Get("testKey", &hereImpossible) == nil {
+	if c.Get(context.TODO(), "testKey", &hereImpossible) == nil {
 		t.Fatalf("Error was expected")
 	}
 }
 
 func TestDeleteOK(t *testing.T) {
 	db, mock := redismock.NewClientMock()
-	mock.ExpectDel("testKey").SetVal(1)
+	mock.ExpectDel("default:testKey").SetVal(1)
 	c := cache.NewRedisCache(testLogger, 0, db)
-	if c.Del("testKey") != nil {
+	if c.Del(context.TODO(), "testKey") != nil {
 		t.Fatalf("Error was not expected")
 	}
 }
 
 func TestDeleteKeyNotFound(t *testing.T) {
 	db, mock := redismock.NewClientMock()
-	mock.ExpectDel("testKey").SetVal(0)
+	mock.ExpectDel("default:testKey").SetVal(0)
 	c := cache.NewRedisCache(testLogger, 0, db)
-	if c.Del("testKey") == nil {
+	if c.Del(context.TODO(), "testKey") == nil {
 		t.Fatalf("Error was expected")
 	}
 }
 
 func TestDeleteCacheError(t *testing.T) {
 	db, mock := redismock.NewClientMock()
-	mock.ExpectDel("testKey").SetErr(fmt.Errorf("cache Error"))
+	mock.ExpectDel("default:testKey").SetErr(fmt.Errorf("cache Error"))
 	c := cache.NewRedisCache(testLogger, 0, db)
-	if c.Del("testKey") == nil {
+	if c.Del(context.TODO(), "testKey") == nil {
 		t.Fatalf("Error was expected")
 	}
 }
@@ -110,7 +111,7 @@ func TestPingOK(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	mock.ExpectPing().SetVal("ok")
 	c := cache.NewRedisCache(testLogger, 0, db)
-	if c.Alive() != true {
+	if c.Alive(context.TODO()) != true {
 		t.Fatalf("true was expected")
 	}
 }
@@ -119,7 +120,7 @@ func TestPingError(t *testing.T) {
 	db, mock := redismock.NewClientMock()
 	mock.ExpectPing().SetErr(fmt.Errorf("Cache not ready"))
 	c := cache.NewRedisCache(testLogger, 0, db)
-	if c.Alive() == true {
+	if c.Alive(context.TODO()) == true {
 		t.Fatalf("true was not expected")
 	}
 }
@@ -0,0 +1,188 @@
+//Package cachetest is a conformance suite for cache.Cache implementations
+package cachetest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+//Harness builds the cache under test and controls its environment
+type Harness struct {
+	//New gives an empty cache whose keys expire after ttl, 0 meaning no expiry
+	New func(t *testing.T, ttl time.Duration) cache.Cache
+	//Advance moves the clock of the cache forward. When nil the suite sleeps.
+	Advance func(t *testing.T, d time.Duration)
+	//Stop takes the backend of the last cache built down. When nil Alive is only checked on a running backend.
+	Stop func(t *testing.T)
+}
+
+type value struct {
+	Name  string
+	Count int
+	Tags  []string
+}
+
+//Run runs the conformance suite as subtests of t
+func Run(t *testing.T, h Harness) {
+	ctx := context.TODO()
+
+	t.Run("SetGetRoundtrip", func(t *testing.T) {
+		c := h.New(t, 0)
+		in := value{Name: "some value", Count: 3, Tags: []string{"a", "b"}}
+		if err := c.Set(ctx, "key", in); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		out := value{}
+		if err := c.Get(ctx, "key", &out); err != nil {
+			t.Fatalf("Get failed: %v", err)
+		}
+		if out.Name != in.Name || out.Count != in.Count || len(out.Tags) != 2 || out.Tags[1] != "b" {
+			t.Fatalf("Expected %+v, got %+v", in, out)
+		}
+	})
+
+	t.Run("GetMissing", func(t *testing.T) {
+		c := h.New(t, 0)
+		out := value{}
+		if err := c.Get(ctx, "missing", &out); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Overwrite", func(t *testing.T) {
+		c := h.New(t, 0)
+		c.Set(ctx, "key", value{Name: "first"})
+		if err := c.Set(ctx, "key", value{Name: "second"}); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		out := value{}
+		if err := c.Get(ctx, "key", &out); err != nil || out.Name != "second" {
+			t.Fatalf("Expected the second value, got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		c := h.New(t, 0)
+		c.Set(ctx, "key", value{Name: "some value"})
+		if err := c.Del(ctx, "key"); err != nil {
+			t.Fatalf("Del failed: %v", err)
+		}
+		out := value{}
+		if err := c.Get(ctx, "key", &out); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound after Del, got %v", err)
+		}
+	})
+
+	t.Run("DeleteMissing", func(t *testing.T) {
+		c := h.New(t, 0)
+		if err := c.Del(ctx, "missing"); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Expected ErrNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TenantIsolation", func(t *testing.T) {
+		c := h.New(t, 0)
+		shopA := tenant.NewContext(ctx, "shop-a")
+		shopB := tenant.NewContext(ctx, "shop-b")
+		c.Set(shopA, "key", value{Name: "shop-a value"})
+		c.Set(ctx, "key", value{Name: "default value"})
+
+		out := value{}
+		if err := c.Get(shopB, "key", &out); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Key of another tenant was reachable, got %+v, %v", out, err)
+		}
+		if err := c.Del(shopB, "key"); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Key of another tenant was deletable, got %v", err)
+		}
+		if err := c.Get(shopA, "key", &out); err != nil || out.Name != "shop-a value" {
+			t.Fatalf("Expected the tenant's own value, got %+v, %v", out, err)
+		}
+		if err := c.Get(ctx, "key", &out); err != nil || out.Name != "default value" {
+			t.Fatalf("Expected the default tenant's value, got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("TTLExpiry", func(t *testing.T) {
+		ttl := time.Second
+		c := h.New(t, ttl)
+		c.Set(ctx, "key", value{Name: "some value"})
+		out := value{}
+		if err := c.Get(ctx, "key", &out); err != nil {
+			t.Fatalf("Value was expected before the TTL, got %v", err)
+		}
+
+		advance(t, h, ttl+100*time.Millisecond)
+		if err := c.Get(ctx, "key", &out); !errors.Is(err, cache.ErrNotFound) {
+			t.Fatalf("Value was expected to expire, got %v", err)
+		}
+	})
+
+	t.Run("ConcurrentAccess", func(t *testing.T) {
+		c := h.New(t, 0)
+		const workers = 16
+		const rounds = 20
+
+		wg := sync.WaitGroup{}
+		errs := make(chan error, workers*rounds*3)
+		for w := 0; w < workers; w++ {
+			wg.Add(1)
+			go func(w int) {
+				defer wg.Done()
+				own := fmt.Sprintf("key-%d", w)
+				for i := 0; i < rounds; i++ {
+					if err := c.Set(ctx, own, value{Count: i}); err != nil {
+						errs <- err
+					}
+					if err := c.Set(ctx, "shared", value{Count: w}); err != nil {
+						errs <- err
+					}
+					out := value{}
+					if err := c.Get(ctx, own, &out); err != nil {
+						errs <- err
+					} else if out.Count != i {
+						errs <- fmt.Errorf("%s: expected %d, got %d", own, i, out.Count)
+					}
+				}
+			}(w)
+		}
+		wg.Wait()
+		close(errs)
+		for err := range errs {
+			t.Fatalf("Concurrent access failed: %v", err)
+		}
+
+		out := value{}
+		if err := c.Get(ctx, "shared", &out); err != nil || out.Count < 0 || out.Count >= workers {
+			t.Fatalf("Shared key was expected to hold one of the written values, got %+v, %v", out, err)
+		}
+	})
+
+	t.Run("Alive", func(t *testing.T) {
+		c := h.New(t, 0)
+		if !c.Alive(ctx) {
+			t.Fatalf("Cache was expected to be alive")
+		}
+		if h.Stop == nil {
+			return
+		}
+		h.Stop(t)
+		if c.Alive(ctx) {
+			t.Fatalf("Cache was not expected to be alive once its backend is down")
+		}
+	})
+}
+
+func advance(t *testing.T, h Harness, d time.Duration) {
+	if h.Advance != nil {
+		h.Advance(t, d)
+		return
+	}
+	time.Sleep(d)
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+//Modes Redis can be deployed in
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+)
+
+//RedisConfig describes how to reach Redis
+type RedisConfig struct {
+	Mode string
+	//Addrs is the server in standalone mode, the sentinels in sentinel mode and the seed nodes in cluster mode
+	Addrs []string
+	//MasterName is the name sentinels know the master by
+	MasterName string
+
+	Username         string
+	Password         string
+	SentinelPassword string
+	//DB is not supported in cluster mode
+	DB int
+
+	TLS bool
+	//TLSCAFile is a PEM bundle trusted on top of the system roots
+	TLSCAFile             string
+	TLSInsecureSkipVerify bool
+
+	//PoolSize and timeouts keep the go-redis defaults when 0
+	PoolSize     int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+//NewRedisClient gives a client for the configured mode. Every mode is a redis.UniversalClient,
+//so the cache and the collab broker work the same on all of them.
+func NewRedisClient(cfg RedisConfig) (redis.UniversalClient, error) {
+	if len(cfg.Addrs) == 0 {
+		return nil, fmt.Errorf("redis: no address configured")
+	}
+	opts := &redis.UniversalOptions{
+		Addrs:            cfg.Addrs,
+		MasterName:       cfg.MasterName,
+		Username:         cfg.Username,
+		Password:         cfg.Password,
+		SentinelPassword: cfg.SentinelPassword,
+		DB:               cfg.DB,
+		PoolSize:         cfg.PoolSize,
+		DialTimeout:      cfg.DialTimeout,
+		ReadTimeout:      cfg.ReadTimeout,
+		WriteTimeout:     cfg.WriteTimeout,
+	}
+	if cfg.TLS {
+		tlsConfig, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts.TLSConfig = tlsConfig
+	}
+
+	switch cfg.Mode {
+	case ModeStandalone, "":
+		if len(cfg.Addrs) > 1 {
+			return nil, fmt.Errorf("redis: standalone mode takes a single address, got %d", len(cfg.Addrs))
+		}
+		return redis.NewClient(opts.Simple()), nil
+	case ModeSentinel:
+		if cfg.MasterName == "" {
+			return nil, fmt.Errorf("redis: sentinel mode needs the master name")
+		}
+		return redis.NewFailoverClient(opts.Failover()), nil
+	case ModeCluster:
+		if cfg.DB != 0 {
+			return nil, fmt.Errorf("redis: cluster mode only supports DB 0")
+		}
+		return redis.NewClusterClient(opts.Cluster()), nil
+	default:
+		return nil, fmt.Errorf("redis: unknown mode %q", cfg.Mode)
+	}
+}
+
+func newTLSConfig(cfg RedisConfig) (*tls.Config, error) {
+	tlsConfig := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.TLSInsecureSkipVerify,
+	}
+	if cfg.TLSCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	pem, err := os.ReadFile(cfg.TLSCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("redis: reading CA file: %w", err)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("redis: no certificate found in %s", cfg.TLSCAFile)
+	}
+	tlsConfig.RootCAs = roots
+	return tlsConfig, nil
+}
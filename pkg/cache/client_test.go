@@ -0,0 +1,119 @@
+package cache_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+func TestNewRedisClientStandalone(t *testing.T) {
+	server := miniredis.RunT(t)
+	client, err := cache.NewRedisClient(cache.RedisConfig{
+		Addrs: []string{server.Addr()},
+		DB:    2,
+	})
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if _, ok := client.(*redis.Client); !ok {
+		t.Fatalf("Expected a single node client")
+	}
+
+	c := cache.NewRedisCache(testLogger, 0, client)
+	if err := c.Set(context.TODO(), "key", "value"); err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	server.Select(2)
+	if !server.Exists("default:key") {
+		t.Fatalf("Key was expected on the configured DB")
+	}
+}
+
+func TestNewRedisClientCluster(t *testing.T) {
+	client, err := cache.NewRedisClient(cache.RedisConfig{
+		Mode:  cache.ModeCluster,
+		Addrs: []string{"node1:6379", "node2:6379"},
+	})
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if _, ok := client.(*redis.ClusterClient); !ok {
+		t.Fatalf("Expected a cluster client")
+	}
+}
+
+func TestNewRedisClientSentinel(t *testing.T) {
+	client, err := cache.NewRedisClient(cache.RedisConfig{
+		Mode:       cache.ModeSentinel,
+		Addrs:      []string{"sentinel1:26379", "sentinel2:26379"},
+		MasterName: "mymaster",
+	})
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if client == nil {
+		t.Fatalf("Expected a failover client")
+	}
+}
+
+func TestNewRedisClientInvalid(t *testing.T) {
+	badCA := filepath.Join(t.TempDir(), "ca.pem")
+	os.WriteFile(badCA, []byte("not a certificate"), 0600)
+
+	configs := map[string]cache.RedisConfig{
+		"no address":               {},
+		"standalone with several":  {Addrs: []string{"a:6379", "b:6379"}},
+		"sentinel without master":  {Mode: cache.ModeSentinel, Addrs: []string{"sentinel:26379"}},
+		"cluster with DB":          {Mode: cache.ModeCluster, Addrs: []string{"node:6379"}, DB: 1},
+		"unknown mode":             {Mode: "ring", Addrs: []string{"node:6379"}},
+		"missing CA file":          {Addrs: []string{"a:6379"}, TLS: true, TLSCAFile: "missing.pem"},
+		"CA file without any cert": {Addrs: []string{"a:6379"}, TLS: true, TLSCAFile: badCA},
+	}
+	for name, cfg := range configs {
+		if _, err := cache.NewRedisClient(cfg); err == nil {
+			t.Fatalf("Error was expected for %s", name)
+		}
+	}
+}
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewRedisCache(testLogger, 0, client, cache.WithKeyPrefix("carts"))
+
+	c.Set(tenant.NewContext(context.TODO(), "shop-a"), "cart1", "value")
+	if !server.Exists("carts:shop-a:cart1") {
+		t.Fatalf("Key was expected under the prefix and tenant, got %v", server.Keys())
+	}
+}
+
+func TestRedisCacheLegacyKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewRedisCache(testLogger, 0, client, cache.WithKeyPrefix("carts"), cache.WithLegacyKeys())
+	server.Set("cart1", `{"id":"cart1"}`)
+	server.Set("cart2", `{"id":"cart2"}`)
+
+	value := map[string]string{}
+	if err := c.Get(context.TODO(), "cart1", &value); err != nil || value["id"] != "cart1" {
+		t.Fatalf("The legacy key was expected to be read: %v", err)
+	}
+	if err := c.Get(tenant.NewContext(context.TODO(), "shop-a"), "cart1", &value); err != cache.ErrNotFound {
+		t.Fatalf("Legacy keys were expected to belong to the default tenant only: %v", err)
+	}
+
+	c.Set(context.TODO(), "cart1", map[string]string{"id": "cart1"})
+	if server.Exists("cart1") || !server.Exists("carts:default:cart1") {
+		t.Fatalf("The cart was expected to move to its namespaced key, got %v", server.Keys())
+	}
+	if err := c.Del(context.TODO(), "cart2"); err != nil || server.Exists("cart2") {
+		t.Fatalf("The legacy key was expected to be deleted: %v", err)
+	}
+}
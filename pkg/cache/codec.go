@@ -0,0 +1,133 @@
+package cache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+//Names of the available codecs
+const (
+	CodecJSON     = "json"
+	CodecMsgpack  = "msgpack"
+	CodecGzipJSON = "gzip-json"
+)
+
+//Codec turns cached values into bytes and back
+type Codec interface {
+	//ID is the version byte written in front of every value, so values keep decoding after the codec changes.
+	//IDs are never reused.
+	ID() byte
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+//codecs are all the codecs values may have been written with, by ID
+var codecs = map[byte]Codec{}
+
+func init() {
+	for _, c := range []Codec{jsonCodec{}, msgpackCodec{}, gzipJSONCodec{}} {
+		codecs[c.ID()] = c
+	}
+}
+
+//NewCodec gives the codec with the given name
+func NewCodec(name string) (Codec, error) {
+	for _, c := range codecs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("unknown cache codec %q", name)
+}
+
+//encode prefixes the value encoded by codec with the codec ID
+func encode(codec Codec, v interface{}) ([]byte, error) {
+	payload, err := codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{codec.ID()}, payload...), nil
+}
+
+//decode uses the codec the value was written with.
+//Values written before codecs existed have no ID and are plain JSON.
+func decode(data []byte, v interface{}) error {
+	if len(data) > 0 {
+		if c, ok := codecs[data[0]]; ok {
+			return c.Unmarshal(data[1:], v)
+		}
+	}
+	return json.Unmarshal(data, v)
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) ID() byte     { return 1 }
+func (jsonCodec) Name() string { return CodecJSON }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+//msgpackCodec is more compact and faster than JSON. It reads the json tags, so the field names are the same.
+type msgpackCodec struct{}
+
+func (msgpackCodec) ID() byte     { return 2 }
+func (msgpackCodec) Name() string { return CodecMsgpack }
+
+func (msgpackCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	enc := msgpack.NewEncoder(buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (msgpackCodec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
+
+//gzipJSONCodec trades CPU for memory, worth it on large carts
+type gzipJSONCodec struct{}
+
+func (gzipJSONCodec) ID() byte     { return 3 }
+func (gzipJSONCodec) Name() string { return CodecGzipJSON }
+
+func (gzipJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	zw := gzip.NewWriter(buf)
+	if err := json.NewEncoder(zw).Encode(v); err != nil {
+		return nil, err
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(b, v)
+}
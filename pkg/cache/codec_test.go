@@ -0,0 +1,67 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache/cachetest"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+var codecNames = []string{cache.CodecJSON, cache.CodecMsgpack, cache.CodecGzipJSON}
+
+func TestCodecConformance(t *testing.T) {
+	for _, name := range codecNames {
+		codec, err := cache.NewCodec(name)
+		if err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		t.Run(name, func(t *testing.T) {
+			cachetest.Run(t, miniredisHarness(func(c cache.Cache) cache.Cache { return c }, cache.WithCodec(codec)))
+		})
+	}
+}
+
+func TestCodecChange(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	cart := models.Cart{ID: "cart1", Items: []models.Item{{ID: "1", Name: "Some Item", Quantity: 2, Price: 1.5}}}
+
+	//values written with every codec must be readable whatever the configured codec
+	for _, written := range codecNames {
+		writer, _ := cache.NewCodec(written)
+		cache.NewRedisCache(testLogger, 0, client, cache.WithCodec(writer)).Set(context.TODO(), written, cart)
+
+		for _, reading := range codecNames {
+			reader, _ := cache.NewCodec(reading)
+			got := models.Cart{}
+			err := cache.NewRedisCache(testLogger, 0, client, cache.WithCodec(reader)).Get(context.TODO(), written, &got)
+			if err != nil || got.ID != "cart1" || len(got.Items) != 1 || got.Items[0] != cart.Items[0] {
+				t.Fatalf("%s value read with %s: got %+v, %v", written, reading, got, err)
+			}
+		}
+	}
+}
+
+func TestCodecLegacyJSON(t *testing.T) {
+	server := miniredis.RunT(t)
+	server.Set("default:cart1", `{"ID":"cart1","Items":[{"ID":"1","Quantity":2}]}`)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	codec, _ := cache.NewCodec(cache.CodecMsgpack)
+
+	got := models.Cart{}
+	err := cache.NewRedisCache(testLogger, 0, client, cache.WithCodec(codec)).Get(context.TODO(), "cart1", &got)
+	if err != nil || len(got.Items) != 1 || got.Items[0].Quantity != 2 {
+		t.Fatalf("Values without version byte were expected to decode as JSON, got %+v, %v", got, err)
+	}
+}
+
+func TestNewCodecUnknown(t *testing.T) {
+	if _, err := cache.NewCodec("xml"); err == nil {
+		t.Fatalf("Error was expected")
+	}
+}
@@ -0,0 +1,48 @@
+package cache_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache/cachetest"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//miniredisHarness runs the suite on an in-process Redis, wrapping the redis cache with wrap
+func miniredisHarness(wrap func(cache.Cache) cache.Cache, opts ...cache.Option) cachetest.Harness {
+	var server *miniredis.Miniredis
+	return cachetest.Harness{
+		New: func(t *testing.T, ttl time.Duration) cache.Cache {
+			server = miniredis.RunT(t)
+			client := redis.NewClient(&redis.Options{Addr: server.Addr(), MaxRetries: -1})
+			return wrap(cache.NewRedisCache(testLogger, ttl, client, opts...))
+		},
+		Advance: func(t *testing.T, d time.Duration) {
+			server.FastForward(d)
+		},
+		Stop: func(t *testing.T) {
+			server.Close()
+		},
+	}
+}
+
+func TestRedisCacheConformance(t *testing.T) {
+	cachetest.Run(t, miniredisHarness(func(c cache.Cache) cache.Cache { return c }))
+}
+
+func TestInstrumentedCacheConformance(t *testing.T) {
+	cachetest.Run(t, miniredisHarness(func(c cache.Cache) cache.Cache {
+		return cache.NewInstrumentedCache(c, prometheus.NewRegistry())
+	}))
+}
+
+func TestTracedCacheConformance(t *testing.T) {
+	cachetest.Run(t, miniredisHarness(func(c cache.Cache) cache.Cache {
+		return cache.NewTracedCache(c, trace.NewNoopTracerProvider())
+	}))
+}
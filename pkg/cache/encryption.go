@@ -0,0 +1,261 @@
+package cache
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+//ErrNotEncrypted is returned by the encrypted cache for values written before encryption was enabled,
+//unless plaintext reads are on. Reencrypt takes care of them.
+var ErrNotEncrypted = errors.New("cache value is not encrypted")
+
+//Keyring holds the AES keys by ID. Values are encrypted with the primary key and
+//decrypted with the key they were written with, so keys can be rotated.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+//ParseKeyring reads keys written as "id:base64key", separated by commas or new lines.
+//The first key is the primary one. Keys are 16, 24 or 32 bytes long (AES-128, AES-192 or AES-256).
+func ParseKeyring(spec string) (*Keyring, error) {
+	k := &Keyring{keys: map[string]cipher.AEAD{}}
+	for _, entry := range strings.FieldsFunc(spec, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" || strings.HasPrefix(entry, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(entry, ":")
+		if !ok || id == "" {
+			return nil, fmt.Errorf("encryption key %q is not id:base64key", id)
+		}
+		if _, ok := k.keys[id]; ok {
+			return nil, fmt.Errorf("encryption key %q is repeated", id)
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q is not base64: %w", id, err)
+		}
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, fmt.Errorf("encryption key %q: %w", id, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		if k.primary == "" {
+			k.primary = id
+		}
+		k.keys[id] = aead
+	}
+	if k.primary == "" {
+		return nil, errors.New("no encryption keys")
+	}
+	return k, nil
+}
+
+//LoadKeyring parses the keys in the file at path, one per line. Lines starting with # are ignored.
+func LoadKeyring(path string) (*Keyring, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeyring(string(b))
+}
+
+//Primary is the ID of the key new values are encrypted with
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+//envelope is what the encrypted cache stores in place of the value
+type envelope struct {
+	KeyID string `json:"kid"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+type encryptedCache struct {
+	next           Cache
+	keyring        *Keyring
+	plaintextReads bool
+}
+
+//EncryptionOption customizes the encrypted cache
+type EncryptionOption func(*encryptedCache)
+
+//WithPlaintextReads reads the values written before encryption was enabled as they are, instead of failing
+//with ErrNotEncrypted, so enabling encryption doesn't hide the existing carts. Once Reencrypt has encrypted
+//them it can be turned off, then a plaintext value planted in Redis is never trusted.
+func WithPlaintextReads() EncryptionOption {
+	return func(c *encryptedCache) {
+		c.plaintextReads = true
+	}
+}
+
+//NewEncryptedCache encrypts values with AES-GCM before handing them to next.
+//Values are JSON encoded first, whatever the codec of next is.
+func NewEncryptedCache(next Cache, keyring *Keyring, opts ...EncryptionOption) Cache {
+	c := &encryptedCache{
+		next:    next,
+		keyring: keyring,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+//additionalData binds a ciphertext to its tenant and key, so it can't be copied under another key
+func additionalData(ctx context.Context, key string) []byte {
+	return []byte(tenant.FromContext(ctx) + ":" + key)
+}
+
+func (c *encryptedCache) seal(ctx context.Context, key string, plaintext []byte) (envelope, error) {
+	aead := c.keyring.keys[c.keyring.primary]
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return envelope{}, err
+	}
+	return envelope{
+		KeyID: c.keyring.primary,
+		Nonce: nonce,
+		Data:  aead.Seal(nil, nonce, plaintext, additionalData(ctx, key)),
+	}, nil
+}
+
+func (c *encryptedCache) open(ctx context.Context, key string, env envelope) ([]byte, error) {
+	aead, ok := c.keyring.keys[env.KeyID]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", env.KeyID)
+	}
+	if len(env.Nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid encryption nonce")
+	}
+	return aead.Open(nil, env.Nonce, env.Data, additionalData(ctx, key))
+}
+
+func (c *encryptedCache) Set(ctx context.Context, key string, value interface{}) error {
+	plaintext, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	env, err := c.seal(ctx, key, plaintext)
+	if err != nil {
+		return err
+	}
+	return c.next.Set(ctx, key, env)
+}
+
+func (c *encryptedCache) Get(ctx context.Context, key string, here interface{}) error {
+	//the value may be an envelope or a plaintext one, it is read once and told apart as JSON whatever the codec
+	var stored interface{}
+	if err := c.next.Get(ctx, key, &stored); err != nil {
+		return err
+	}
+	doc, err := json.Marshal(stored)
+	if err != nil {
+		return err
+	}
+	env := envelope{}
+	if json.Unmarshal(doc, &env) != nil || env.KeyID == "" {
+		if !c.plaintextReads {
+			return ErrNotEncrypted
+		}
+		return json.Unmarshal(doc, here)
+	}
+	plaintext, err := c.open(ctx, key, env)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(plaintext, here)
+}
+
+func (c *encryptedCache) Del(ctx context.Context, key string) error {
+	return c.next.Del(ctx, key)
+}
+
+func (c *encryptedCache) Alive(ctx context.Context) bool {
+	return c.next.Alive(ctx)
+}
+
+//rotate encrypts the value at key with the primary key, unless it already is
+func (c *encryptedCache) rotate(ctx context.Context, store *redisCache, key string, legacy bool) (bool, error) {
+	return store.update(ctx, key, legacy, func(decode func(here interface{}) error) (interface{}, error) {
+		env := envelope{}
+		if err := decode(&env); err == nil && env.KeyID != "" {
+			if env.KeyID == c.keyring.primary {
+				return nil, nil
+			}
+			plaintext, err := c.open(ctx, key, env)
+			if err != nil {
+				return nil, err
+			}
+			return c.seal(ctx, key, plaintext)
+		}
+		//written before encryption was enabled
+		var value interface{}
+		if err := decode(&value); err != nil {
+			return nil, err
+		}
+		plaintext, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		return c.seal(ctx, key, plaintext)
+	})
+}
+
+//Reencrypt walks every key of an encrypted redis cache, of every tenant, and rewrites the values
+//not encrypted with the primary key, plaintext ones included. It runs next to the service:
+//a value written by the service while it is being rotated is left as the service wrote it.
+//With legacy keys, the bare keys of the default tenant are moved to their namespaced key as they are rotated,
+//so they stay readable once plaintext reads are turned off.
+//A value that can't be rotated, like one sealed with a key no longer in the keyring, is logged and
+//skipped, the error tells how many were once every other value is rotated.
+func Reencrypt(ctx context.Context, c Cache) (rotated int, err error) {
+	enc, ok := c.(*encryptedCache)
+	if !ok {
+		return 0, errors.New("re-encryption needs an encrypted cache")
+	}
+	store, ok := enc.next.(*redisCache)
+	if !ok {
+		return 0, errors.New("re-encryption needs an encrypted cache over a redis cache")
+	}
+	failed := 0
+	var firstErr error
+	err = store.scan(ctx, func(ctx context.Context, key string, legacy bool) error {
+		done, err := enc.rotate(ctx, store, key, legacy)
+		if err != nil {
+			store.logger.WithContext(ctx).WithError(err).WithField("key", key).Error("reencrypt_error")
+			if firstErr == nil {
+				firstErr = err
+			}
+			failed++
+			return nil
+		}
+		if done {
+			rotated++
+		}
+		return nil
+	})
+	if err != nil {
+		return rotated, err
+	}
+	if failed > 0 {
+		return rotated, fmt.Errorf("%d values could not be re-encrypted, the first one: %w", failed, firstErr)
+	}
+	return rotated, nil
+}
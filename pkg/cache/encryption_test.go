@@ -0,0 +1,236 @@
+package cache_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache/cachetest"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+)
+
+const (
+	oldKey   = "2026-01:MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="
+	newKey   = "2026-10:ZmVkY2JhOTg3NjU0MzIxMGZlZGNiYTk4NzY1NDMyMTA="
+	otherKey = "2027-04:YWJjZGVmZ2hpamtsbW5vcHFyc3R1dnd4eXowMTIzNDU="
+)
+
+func keyring(t *testing.T, spec string) *cache.Keyring {
+	k, err := cache.ParseKeyring(spec)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	return k
+}
+
+func TestEncryptedCacheConformance(t *testing.T) {
+	k := keyring(t, newKey)
+	for _, name := range codecNames {
+		codec, _ := cache.NewCodec(name)
+		t.Run(name, func(t *testing.T) {
+			cachetest.Run(t, miniredisHarness(func(c cache.Cache) cache.Cache {
+				return cache.NewEncryptedCache(c, k)
+			}, cache.WithCodec(codec)))
+		})
+	}
+}
+
+func TestParseKeyring(t *testing.T) {
+	k := keyring(t, "# rotated on october\n"+newKey+"\n"+oldKey+"\n")
+	if k.Primary() != "2026-10" {
+		t.Fatalf("Unexpected primary key %s", k.Primary())
+	}
+
+	for _, spec := range []string{"", "nokey", "id:not base64", "id:c2hvcnQ=", newKey + "," + newKey} {
+		if _, err := cache.ParseKeyring(spec); err == nil {
+			t.Fatalf("Error was expected for %q", spec)
+		}
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	os.WriteFile(path, []byte(newKey+"\n"+oldKey+"\n"), 0600)
+
+	k, err := cache.LoadKeyring(path)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if k.Primary() != "2026-10" {
+		t.Fatalf("Unexpected primary key %s", k.Primary())
+	}
+}
+
+func TestEncryptedCacheStoresCiphertext(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewEncryptedCache(cache.NewRedisCache(testLogger, 0, client), keyring(t, newKey))
+
+	c.Set(context.TODO(), "cart1", models.Cart{ID: "cart1", Items: []models.Item{{ID: "secret-item"}}})
+
+	stored, _ := server.Get("default:cart1")
+	if strings.Contains(stored, "secret-item") {
+		t.Fatalf("Value was expected to be encrypted: %s", stored)
+	}
+	if !strings.Contains(stored, `"kid":"2026-10"`) {
+		t.Fatalf("Key ID was expected next to the ciphertext: %s", stored)
+	}
+
+	//a ciphertext copied under another key or tenant does not decrypt
+	server.Set("default:cart2", stored)
+	server.Set("shop-a:cart1", stored)
+	got := models.Cart{}
+	if err := c.Get(context.TODO(), "cart2", &got); err == nil {
+		t.Fatalf("Error was expected for a moved ciphertext")
+	}
+	if err := c.Get(tenant.NewContext(context.TODO(), "shop-a"), "cart1", &got); err == nil {
+		t.Fatalf("Error was expected for a ciphertext of another tenant")
+	}
+}
+
+func TestEncryptedCacheMissingAndPlaintext(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	c := cache.NewEncryptedCache(cache.NewRedisCache(testLogger, 0, client), keyring(t, newKey))
+
+	got := models.Cart{}
+	if err := c.Get(context.TODO(), "missing", &got); !errors.Is(err, cache.ErrNotFound) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	server.Set("default:plain", `{"ID":"plain"}`)
+	if err := c.Get(context.TODO(), "plain", &got); !errors.Is(err, cache.ErrNotEncrypted) {
+		t.Fatalf("Unexpected error %v", err)
+	}
+}
+
+func TestEncryptedCachePlaintextReads(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	msgpack, _ := cache.NewCodec(cache.CodecMsgpack)
+	plain := cache.NewRedisCache(testLogger, 0, client, cache.WithCodec(msgpack))
+	c := cache.NewEncryptedCache(plain, keyring(t, newKey), cache.WithPlaintextReads())
+
+	server.Set("default:plain", `{"ID":"plain","Items":[{"ID":"1","Quantity":2}]}`)
+	plain.Set(context.TODO(), "packed", models.Cart{ID: "packed"})
+	c.Set(context.TODO(), "sealed", models.Cart{ID: "sealed"})
+
+	for _, id := range []string{"plain", "packed", "sealed"} {
+		got := models.Cart{}
+		if err := c.Get(context.TODO(), id, &got); err != nil || got.ID != id {
+			t.Fatalf("Unexpected cart %+v: %v", got, err)
+		}
+	}
+	got := models.Cart{}
+	c.Get(context.TODO(), "plain", &got)
+	if len(got.Items) != 1 || got.Items[0].Quantity != 2 {
+		t.Fatalf("Unexpected items %+v", got.Items)
+	}
+}
+
+func TestReencrypt(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := func() cache.Cache {
+		return cache.NewRedisCache(testLogger, 0, client, cache.WithKeyPrefix("svc"))
+	}
+	shopA := tenant.NewContext(context.TODO(), "shop-a")
+
+	old := cache.NewEncryptedCache(store(), keyring(t, oldKey))
+	old.Set(context.TODO(), "cart1", models.Cart{ID: "cart1"})
+	old.Set(shopA, "cart2", models.Cart{ID: "cart2"})
+	store().Set(context.TODO(), "cart3", models.Cart{ID: "cart3"})
+	server.SetTTL("svc:shop-a:cart2", time.Hour)
+	//not written by the cache
+	server.HSet("svc:cart_presence:default:cart1", "user", "1")
+
+	rotating := cache.NewEncryptedCache(store(), keyring(t, newKey+","+oldKey))
+	rotating.Set(context.TODO(), "cart4", models.Cart{ID: "cart4"})
+
+	rotated, err := cache.Reencrypt(context.TODO(), rotating)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if rotated != 3 {
+		t.Fatalf("Unexpected rotated values %d", rotated)
+	}
+	if server.TTL("svc:shop-a:cart2") != time.Hour {
+		t.Fatalf("Expiry was expected to be kept")
+	}
+
+	//the old key can be dropped
+	current := cache.NewEncryptedCache(store(), keyring(t, newKey))
+	for _, tc := range []struct {
+		ctx context.Context
+		id  string
+	}{
+		{context.TODO(), "cart1"},
+		{shopA, "cart2"},
+		{context.TODO(), "cart3"},
+		{context.TODO(), "cart4"},
+	} {
+		got := models.Cart{}
+		if err := current.Get(tc.ctx, tc.id, &got); err != nil {
+			t.Fatalf("Error was not expected for %s: %v", tc.id, err)
+		}
+		if got.ID != tc.id {
+			t.Fatalf("Unexpected cart %s", got.ID)
+		}
+	}
+
+	//sealed with a key that is gone, the others are rotated anyway
+	cache.NewEncryptedCache(store(), keyring(t, oldKey)).Set(context.TODO(), "lost", models.Cart{ID: "lost"})
+	newer := cache.NewEncryptedCache(store(), keyring(t, otherKey+","+newKey))
+	rotated, err = cache.Reencrypt(context.TODO(), newer)
+	if err == nil || rotated != 4 {
+		t.Fatalf("Unexpected rotated values %d: %v", rotated, err)
+	}
+
+	if _, err := cache.Reencrypt(context.TODO(), store()); err == nil {
+		t.Fatalf("Error was expected for a cache that is not encrypted")
+	}
+}
+
+func TestReencryptLegacyKeys(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	store := cache.NewRedisCache(testLogger, 0, client, cache.WithKeyPrefix("svc"), cache.WithLegacyKeys())
+	k := keyring(t, newKey)
+
+	//written before keys were namespaced
+	server.Set("cart1", `{"id":"cart1"}`)
+	server.SetTTL("cart1", time.Hour)
+	server.Set("cart2", `{"id":"stale"}`)
+	cache.NewEncryptedCache(cache.NewRedisCache(testLogger, 0, client, cache.WithKeyPrefix("svc")), k).Set(context.TODO(), "cart2", models.Cart{ID: "cart2"})
+	//not a legacy key
+	server.Set("other:app", "value")
+
+	rotated, err := cache.Reencrypt(context.TODO(), cache.NewEncryptedCache(store, k, cache.WithPlaintextReads()))
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if rotated != 1 {
+		t.Fatalf("Unexpected rotated values %d", rotated)
+	}
+	if server.Exists("cart1") || server.TTL("svc:default:cart1") != time.Hour {
+		t.Fatalf("Legacy key was expected to move keeping its expiry")
+	}
+	if !server.Exists("cart2") || !server.Exists("other:app") {
+		t.Fatalf("Keys were not expected to be touched")
+	}
+
+	//readable once plaintext reads are turned off
+	got := models.Cart{}
+	if err := cache.NewEncryptedCache(store, k).Get(context.TODO(), "cart1", &got); err != nil || got.ID != "cart1" {
+		t.Fatalf("Unexpected cart %s: %v", got.ID, err)
+	}
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+//Operations of the cache, as used by LogPolicy.Disabled
+const (
+	OperationSet  = "set"
+	OperationGet  = "get"
+	OperationDel  = "del"
+	OperationPing = "ping"
+)
+
+//How values written to the cache show up in the logs
+const (
+	ValueOmit     = "omit"
+	ValueHash     = "hash"
+	ValueTruncate = "truncate"
+	ValueFull     = "full"
+)
+
+const redacted = "[REDACTED]"
+
+//LogPolicy decides what the cache logs about each operation.
+//Errors are always logged at error level, whatever the policy.
+type LogPolicy struct {
+	//Level is the level operations are logged at
+	Level logrus.Level
+	//Disabled turns off logging for the given operations
+	Disabled []string
+	//Values is how written values are logged: omit, hash, truncate or full. Unknown modes hash them.
+	Values string
+	//MaxValueBytes is where truncated values are cut
+	MaxValueBytes int
+	//RedactFields are JSON fields, at any depth, whose value is replaced before logging. Case insensitive.
+	RedactFields []string
+}
+
+//DefaultLogPolicy logs operations at debug level with a hash of the written values
+func DefaultLogPolicy() LogPolicy {
+	return LogPolicy{
+		Level:         logrus.DebugLevel,
+		Values:        ValueHash,
+		MaxValueBytes: 256,
+		RedactFields:  []string{"email", "address", "phone"},
+	}
+}
+
+func (p LogPolicy) enabled(operation string) bool {
+	for _, op := range p.Disabled {
+		if op == operation {
+			return false
+		}
+	}
+	return true
+}
+
+//valueFields gives the log fields describing a value and its stored form
+func (p LogPolicy) valueFields(value interface{}, stored []byte) logrus.Fields {
+	fields := logrus.Fields{"value_size": len(stored)}
+	switch p.Values {
+	case ValueOmit:
+		return fields
+	case ValueTruncate, ValueFull:
+	default:
+		//a typo in the mode must never log values
+		sum := sha256.Sum256(stored)
+		fields["value_sha256"] = hex.EncodeToString(sum[:])
+		return fields
+	}
+
+	//whatever the codec, values are logged as JSON so fields can be redacted
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fields
+	}
+	logged := string(p.redact(encoded))
+	if p.Values == ValueTruncate && p.MaxValueBytes >= 0 && len(logged) > p.MaxValueBytes {
+		//cut on a rune boundary, so the log stays valid UTF-8
+		cut := p.MaxValueBytes
+		for cut > 0 && !utf8.RuneStart(logged[cut]) {
+			cut--
+		}
+		logged = logged[:cut] + "..."
+	}
+	fields["value"] = logged
+	return fields
+}
+
+//redact replaces the value of the RedactFields in a JSON document
+func (p LogPolicy) redact(encoded []byte) []byte {
+	if len(p.RedactFields) == 0 {
+		return encoded
+	}
+	var doc interface{}
+	if err := json.Unmarshal(encoded, &doc); err != nil {
+		return encoded
+	}
+	b, err := json.Marshal(p.redactValue(doc))
+	if err != nil {
+		return encoded
+	}
+	return b
+}
+
+func (p LogPolicy) redactValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if p.sensitive(key) {
+				value[key] = redacted
+				continue
+			}
+			value[key] = p.redactValue(field)
+		}
+	case []interface{}:
+		for i := range value {
+			value[i] = p.redactValue(value[i])
+		}
+	}
+	return v
+}
+
+func (p LogPolicy) sensitive(field string) bool {
+	for _, f := range p.RedactFields {
+		if strings.EqualFold(f, field) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,141 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+
+	"github.com/go-redis/redismock/v8"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+type customer struct {
+	Name    string            `json:"name"`
+	Email   string            `json:"email"`
+	Details map[string]string `json:"details"`
+}
+
+func setWithPolicy(t *testing.T, policy cache.LogPolicy, value interface{}) *logtest.Hook {
+	logger, hook := logtest.NewNullLogger()
+	logger.SetLevel(logrus.DebugLevel)
+	db, mock := redismock.NewClientMock()
+	b, _ := json.Marshal(value)
+	mock.ExpectSet("default:testKey", "\x01"+string(b), 0).SetVal("OK")
+
+	c := cache.NewRedisCache(logger, 0, db, cache.WithLogPolicy(policy))
+	if c.Set(context.TODO(), "testKey", value) != nil {
+		t.Fatalf("Error was not expected")
+	}
+	return hook
+}
+
+func TestDefaultLogPolicyHashesValue(t *testing.T) {
+	hook := setWithPolicy(t, cache.DefaultLogPolicy(), customer{Email: "someone@example.com"})
+
+	entry := hook.LastEntry()
+	if entry == nil || entry.Level != logrus.DebugLevel {
+		t.Fatalf("Expected a debug entry")
+	}
+	if _, ok := entry.Data["value"]; ok {
+		t.Fatalf("Value was not expected in the logs")
+	}
+	if len(entry.Data["value_sha256"].(string)) != 64 {
+		t.Fatalf("Expected the hash of the value")
+	}
+}
+
+func TestLogPolicyRedactsFields(t *testing.T) {
+	policy := cache.LogPolicy{
+		Level:        logrus.InfoLevel,
+		Values:       cache.ValueFull,
+		RedactFields: []string{"Email", "address"},
+	}
+	hook := setWithPolicy(t, policy, customer{
+		Name:    "Someone",
+		Email:   "someone@example.com",
+		Details: map[string]string{"address": "Some Street 123"},
+	})
+
+	value := hook.LastEntry().Data["value"].(string)
+	if strings.Contains(value, "someone@example.com") || strings.Contains(value, "Some Street") {
+		t.Fatalf("Sensitive fields were expected to be redacted: %s", value)
+	}
+	if !strings.Contains(value, "Someone") {
+		t.Fatalf("Other fields were expected to be kept: %s", value)
+	}
+}
+
+func TestLogPolicyTruncatesValue(t *testing.T) {
+	policy := cache.LogPolicy{
+		Level:         logrus.InfoLevel,
+		Values:        cache.ValueTruncate,
+		MaxValueBytes: 10,
+	}
+	hook := setWithPolicy(t, policy, strings.Repeat("a", 100))
+
+	entry := hook.LastEntry()
+	if entry.Data["value"] != `"aaaaaaaaa...` {
+		t.Fatalf("Unexpected truncated value %v", entry.Data["value"])
+	}
+	if entry.Data["value_size"] != 103 {
+		t.Fatalf("Unexpected value size %v", entry.Data["value_size"])
+	}
+}
+
+func TestLogPolicyTruncatesOnRuneBoundary(t *testing.T) {
+	policy := cache.LogPolicy{
+		Level:         logrus.InfoLevel,
+		Values:        cache.ValueTruncate,
+		MaxValueBytes: 10,
+	}
+	hook := setWithPolicy(t, policy, strings.Repeat("ñ", 100))
+
+	logged := hook.LastEntry().Data["value"].(string)
+	if !utf8.ValidString(logged) || logged != `"ññññ...` {
+		t.Fatalf("Unexpected truncated value %v", logged)
+	}
+}
+
+func TestLogPolicyUnknownModeHashesValue(t *testing.T) {
+	policy := cache.DefaultLogPolicy()
+	policy.Values = "ful"
+	hook := setWithPolicy(t, policy, "secret")
+
+	entry := hook.LastEntry()
+	if _, ok := entry.Data["value"]; ok {
+		t.Fatalf("The value was not expected to be logged")
+	}
+	if _, ok := entry.Data["value_sha256"]; !ok {
+		t.Fatalf("The hash of the value was expected")
+	}
+}
+
+func TestLogPolicyDisabledOperation(t *testing.T) {
+	policy := cache.DefaultLogPolicy()
+	policy.Disabled = []string{cache.OperationSet}
+	hook := setWithPolicy(t, policy, "test")
+
+	if len(hook.Entries) != 0 {
+		t.Fatalf("Set was not expected to be logged")
+	}
+}
+
+func TestLogPolicyKeepsErrors(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	db, mock := redismock.NewClientMock()
+	mock.ExpectPing().SetErr(context.DeadlineExceeded)
+
+	policy := cache.DefaultLogPolicy()
+	policy.Disabled = []string{cache.OperationPing}
+	c := cache.NewRedisCache(logger, 0, db, cache.WithLogPolicy(policy))
+	c.Alive(context.TODO())
+
+	if len(hook.Entries) != 1 || hook.LastEntry().Level != logrus.ErrorLevel {
+		t.Fatalf("Errors were expected to be logged")
+	}
+}
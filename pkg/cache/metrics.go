@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+const (
+	outcomeOK    = "ok"
+	outcomeMiss  = "miss"
+	outcomeError = "error"
+)
+
+type instrumentedCache struct {
+	next       Cache
+	operations *prometheus.CounterVec
+	duration   *prometheus.HistogramVec
+}
+
+//NewInstrumentedCache wraps a Cache recording count and duration of every operation,
+//labelled by operation and outcome (ok, miss or error)
+func NewInstrumentedCache(next Cache, reg prometheus.Registerer) Cache {
+	c := &instrumentedCache{
+		next: next,
+		operations: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "cache_operations_total",
+			Help: "Cache operations by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "cache_operation_duration_seconds",
+			Help:    "Duration of cache operations by operation and outcome.",
+			Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"operation", "outcome"}),
+	}
+	reg.MustRegister(c.operations, c.duration)
+	return c
+}
+
+func (c *instrumentedCache) Set(ctx context.Context, key string, value interface{}) error {
+	start := time.Now()
+	err := c.next.Set(ctx, key, value)
+	c.observe("set", start, outcomeFromError(err))
+	return err
+}
+
+func (c *instrumentedCache) Get(ctx context.Context, key string, here interface{}) error {
+	start := time.Now()
+	err := c.next.Get(ctx, key, here)
+	c.observe("get", start, outcomeFromError(err))
+	return err
+}
+
+func (c *instrumentedCache) Del(ctx context.Context, key string) error {
+	start := time.Now()
+	err := c.next.Del(ctx, key)
+	c.observe("del", start, outcomeFromError(err))
+	return err
+}
+
+func (c *instrumentedCache) Alive(ctx context.Context) bool {
+	start := time.Now()
+	alive := c.next.Alive(ctx)
+	outcome := outcomeOK
+	if !alive {
+		outcome = outcomeError
+	}
+	c.observe("ping", start, outcome)
+	return alive
+}
+
+func (c *instrumentedCache) observe(operation string, start time.Time, outcome string) {
+	c.operations.WithLabelValues(operation, outcome).Inc()
+	c.duration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func outcomeFromError(err error) string {
+	switch {
+	case err == nil:
+		return outcomeOK
+	case errors.Is(err, redis.Nil):
+		return outcomeMiss
+	default:
+		return outcomeError
+	}
+}
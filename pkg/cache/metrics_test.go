@@ -0,0 +1,49 @@
+package cache_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestInstrumentedCacheOutcomes(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	b, _ := json.Marshal("test")
+	mock.ExpectSet("default:testKey", "\x01"+string(b), 0).SetVal("OK")
+	mock.ExpectGet("default:missing").SetErr(redis.Nil)
+	mock.ExpectDel("default:testKey").SetErr(fmt.Errorf("cache Error"))
+	mock.ExpectPing().SetVal("PONG")
+
+	reg := prometheus.NewRegistry()
+	c := cache.NewInstrumentedCache(cache.NewRedisCache(testLogger, 0, db), reg)
+
+	c.Set(context.TODO(), "testKey", "test")
+	str := ""
+	c.Get(context.TODO(), "missing", &str)
+	c.Del(context.TODO(), "testKey")
+	c.Alive(context.TODO())
+
+	expected := `
+# HELP cache_operations_total Cache operations by operation and outcome.
+# TYPE cache_operations_total counter
+cache_operations_total{operation="del",outcome="error"} 1
+cache_operations_total{operation="get",outcome="miss"} 1
+cache_operations_total{operation="ping",outcome="ok"} 1
+cache_operations_total{operation="set",outcome="ok"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected), "cache_operations_total"); err != nil {
+		t.Fatalf("Unexpected metrics: %s", err)
+	}
+	if testutil.CollectAndCount(reg, "cache_operation_duration_seconds") != 4 {
+		t.Fatalf("Durations were expected for every operation and outcome")
+	}
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tracing"
+
+	"github.com/go-redis/redis/v8"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedCache struct {
+	next   Cache
+	tracer trace.Tracer
+}
+
+//NewTracedCache wraps a Cache creating a client span for every operation
+func NewTracedCache(next Cache, tp trace.TracerProvider) Cache {
+	return &tracedCache{
+		next:   next,
+		tracer: tp.Tracer("github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"),
+	}
+}
+
+func (c *tracedCache) start(ctx context.Context, operation string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, semconv.DBSystemRedis, semconv.DBOperation(operation))
+	return c.tracer.Start(ctx, "cache."+operation, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+func (c *tracedCache) Set(ctx context.Context, key string, value interface{}) error {
+	ctx, span := c.start(ctx, "set", attribute.String("cache.key", key))
+	err := c.next.Set(ctx, key, value)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *tracedCache) Get(ctx context.Context, key string, here interface{}) error {
+	ctx, span := c.start(ctx, "get", attribute.String("cache.key", key))
+	err := c.next.Get(ctx, key, here)
+	//a miss is a valid answer from the cache, not a failure of it
+	span.SetAttributes(attribute.Bool("cache.hit", err == nil))
+	if errors.Is(err, redis.Nil) {
+		tracing.End(span, nil)
+		return err
+	}
+	tracing.End(span, err)
+	return err
+}
+
+func (c *tracedCache) Del(ctx context.Context, key string) error {
+	ctx, span := c.start(ctx, "del", attribute.String("cache.key", key))
+	err := c.next.Del(ctx, key)
+	tracing.End(span, err)
+	return err
+}
+
+func (c *tracedCache) Alive(ctx context.Context) bool {
+	ctx, span := c.start(ctx, "ping")
+	alive := c.next.Alive(ctx)
+	span.SetAttributes(attribute.Bool("cache.alive", alive))
+	span.End()
+	return alive
+}
@@ -0,0 +1,52 @@
+package cache_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/go-redis/redismock/v8"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedCacheSpans(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mock.ExpectGet("default:missing").SetErr(redis.Nil)
+	mock.ExpectDel("default:testKey").SetErr(fmt.Errorf("cache Error"))
+	mock.ExpectPing().SetVal("PONG")
+
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	c := cache.NewTracedCache(cache.NewRedisCache(testLogger, 0, db), tp)
+
+	str := ""
+	c.Get(context.TODO(), "missing", &str)
+	c.Del(context.TODO(), "testKey")
+	c.Alive(context.TODO())
+
+	spans := sr.Ended()
+	if len(spans) != 3 {
+		t.Fatalf("Expected one span per operation, got %d", len(spans))
+	}
+	expected := []struct {
+		name   string
+		status codes.Code
+	}{
+		{"cache.get", codes.Unset},
+		{"cache.del", codes.Error},
+		{"cache.ping", codes.Unset},
+	}
+	for i, e := range expected {
+		if spans[i].Name() != e.name {
+			t.Fatalf("Unexpected span name %s", spans[i].Name())
+		}
+		if spans[i].Status().Code != e.status {
+			t.Fatalf("Unexpected status on %s", e.name)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package collab
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+const (
+	EventCartUpdated = "cart_updated"
+	EventPresence    = "presence"
+
+	subscriberBuffer = 16
+)
+
+//Event is what gets broadcasted to every subscriber of a cart
+type Event struct {
+	Type    string       `json:"type"`
+	CartID  string       `json:"cart_id"`
+	Cart    *models.Cart `json:"cart,omitempty"`
+	Viewers []string     `json:"viewers,omitempty"`
+}
+
+//Broker fans out cart events and keeps track of who is viewing each cart.
+//Implementations backed by shared storage let several service instances
+//collaborate on the same cart.
+type Broker interface {
+	Publish(ctx context.Context, event Event) error
+	//Subscribe returns a channel of events for the cart, closed once ctx is done.
+	//It is closed as well when the subscriber falls behind: events were missed and the cart must be fetched again.
+	Subscribe(ctx context.Context, cartID string) (<-chan Event, error)
+	//Join registers a viewer on the cart and returns the current viewers
+	Join(ctx context.Context, cartID, viewer string) ([]string, error)
+	//Refresh keeps the viewers of the cart listed, viewers call it on every heartbeat
+	Refresh(ctx context.Context, cartID string) error
+	//Leave removes a viewer from the cart and returns the remaining viewers
+	Leave(ctx context.Context, cartID, viewer string) ([]string, error)
+}
+
+type memoryBroker struct {
+	mu          sync.Mutex
+	subscribers map[string]map[chan Event]struct{}
+	viewers     map[string]map[string]int
+}
+
+//NewMemoryBroker gives a Broker that only reaches subscribers of this instance
+func NewMemoryBroker() Broker {
+	return &memoryBroker{
+		subscribers: map[string]map[chan Event]struct{}{},
+		viewers:     map[string]map[string]int{},
+	}
+}
+
+func (b *memoryBroker) Publish(ctx context.Context, event Event) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	key := topic(ctx, event.CartID)
+	for ch := range b.subscribers[key] {
+		select {
+		case ch <- event:
+		default:
+			//slow subscriber, it missed the event and has to start over
+			b.unsubscribe(key, ch)
+		}
+	}
+	return nil
+}
+
+func (b *memoryBroker) Subscribe(ctx context.Context, cartID string) (<-chan Event, error) {
+	key := topic(ctx, cartID)
+	ch := make(chan Event, subscriberBuffer)
+
+	b.mu.Lock()
+	if b.subscribers[key] == nil {
+		b.subscribers[key] = map[chan Event]struct{}{}
+	}
+	b.subscribers[key][ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		b.unsubscribe(key, ch)
+		b.mu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+//unsubscribe closes the channel unless it's already gone, it must be called holding the lock
+func (b *memoryBroker) unsubscribe(key string, ch chan Event) {
+	if _, ok := b.subscribers[key][ch]; !ok {
+		return
+	}
+	delete(b.subscribers[key], ch)
+	if len(b.subscribers[key]) == 0 {
+		delete(b.subscribers, key)
+	}
+	close(ch)
+}
+
+func (b *memoryBroker) Join(ctx context.Context, cartID, viewer string) ([]string, error) {
+	key := topic(ctx, cartID)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.viewers[key] == nil {
+		b.viewers[key] = map[string]int{}
+	}
+	b.viewers[key][viewer]++
+	return b.viewersOf(key), nil
+}
+
+//Refresh has nothing to do, viewers of this instance stay listed until they leave
+func (b *memoryBroker) Refresh(ctx context.Context, cartID string) error {
+	return nil
+}
+
+func (b *memoryBroker) Leave(ctx context.Context, cartID, viewer string) ([]string, error) {
+	key := topic(ctx, cartID)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.viewers[key] == nil {
+		return []string{}, nil
+	}
+	b.viewers[key][viewer]--
+	if b.viewers[key][viewer] <= 0 {
+		delete(b.viewers[key], viewer)
+	}
+	viewers := b.viewersOf(key)
+	if len(viewers) == 0 {
+		delete(b.viewers, key)
+	}
+	return viewers, nil
+}
+
+//viewersOf must be called holding the lock
+func (b *memoryBroker) viewersOf(key string) []string {
+	viewers := []string{}
+	for viewer := range b.viewers[key] {
+		viewers = append(viewers, viewer)
+	}
+	sort.Strings(viewers)
+	return viewers
+}
+
+//topic scopes a cart to the tenant of the context, so tenants never see each other's events or viewers
+func topic(ctx context.Context, cartID string) string {
+	return tenant.FromContext(ctx) + ":" + cartID
+}
@@ -0,0 +1,123 @@
+package collab_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+func TestMemoryPublishReachesSubscribers(t *testing.T) {
+	b := collab.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, err := b.Subscribe(ctx, "cart1")
+	if err != nil {
+		t.Fatalf("Error was not expected")
+	}
+	other, _ := b.Subscribe(ctx, "cart2")
+
+	b.Publish(ctx, collab.Event{Type: collab.EventCartUpdated, CartID: "cart1", Cart: &models.Cart{ID: "cart1"}})
+
+	select {
+	case ev := <-events:
+		if ev.Cart == nil || ev.Cart.ID != "cart1" {
+			t.Fatalf("Unexpected event received")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Event was expected")
+	}
+
+	select {
+	case <-other:
+		t.Fatalf("Event for another cart was not expected")
+	default:
+	}
+}
+
+func TestMemorySubscribeClosesOnCancel(t *testing.T) {
+	b := collab.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	events, _ := b.Subscribe(ctx, "cart1")
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		if ok {
+			t.Fatalf("Channel was expected to be closed")
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Channel was expected to be closed")
+	}
+}
+
+func TestMemorySlowSubscriberClosed(t *testing.T) {
+	b := collab.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	events, _ := b.Subscribe(ctx, "cart1")
+	//nobody reads, the buffer fills up and then an event is missed
+	for i := 0; i < 32; i++ {
+		b.Publish(ctx, collab.Event{Type: collab.EventCartUpdated, CartID: "cart1"})
+	}
+
+	received := 0
+	for range events {
+		received++
+	}
+	if received == 0 || received >= 32 {
+		t.Fatalf("Unexpected events received before closing: %d", received)
+	}
+}
+
+func TestMemoryPresence(t *testing.T) {
+	b := collab.NewMemoryBroker()
+	ctx := context.Background()
+
+	b.Join(ctx, "cart1", "bob")
+	b.Join(ctx, "cart1", "alice")
+	viewers, _ := b.Join(ctx, "cart1", "alice")
+	if len(viewers) != 2 || viewers[0] != "alice" || viewers[1] != "bob" {
+		t.Fatalf("Unexpected viewers: %v", viewers)
+	}
+
+	//alice has two connections open, she's still there after closing one
+	viewers, _ = b.Leave(ctx, "cart1", "alice")
+	if len(viewers) != 2 {
+		t.Fatalf("Unexpected viewers: %v", viewers)
+	}
+
+	b.Leave(ctx, "cart1", "alice")
+	viewers, _ = b.Leave(ctx, "cart1", "bob")
+	if len(viewers) != 0 {
+		t.Fatalf("Unexpected viewers: %v", viewers)
+	}
+}
+
+func TestMemoryTenantIsolation(t *testing.T) {
+	b := collab.NewMemoryBroker()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	shopA := tenant.NewContext(ctx, "shop-a")
+	shopB := tenant.NewContext(ctx, "shop-b")
+
+	events, _ := b.Subscribe(shopA, "cart1")
+	b.Publish(shopB, collab.Event{Type: collab.EventCartUpdated, CartID: "cart1"})
+	select {
+	case <-events:
+		t.Fatalf("Event of another tenant was not expected")
+	default:
+	}
+
+	b.Join(shopA, "cart1", "alice")
+	viewers, _ := b.Join(shopB, "cart1", "bob")
+	if len(viewers) != 1 || viewers[0] != "bob" {
+		t.Fatalf("Viewers of another tenant were not expected, got %v", viewers)
+	}
+}
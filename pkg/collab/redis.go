@@ -0,0 +1,147 @@
+package collab
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	channelPrefix  = "cart_events:"
+	presencePrefix = "cart_presence:"
+
+	//presenceTTL bounds how long viewers of a crashed instance stay listed,
+	//viewers still connected refresh it on every heartbeat
+	presenceTTL = 5 * time.Minute
+)
+
+type redisBroker struct {
+	client    redis.UniversalClient
+	logger    *logrus.Logger
+	keyPrefix string
+}
+
+//NewRedisBroker gives a Broker using Redis Pub/Sub for events and a hash per cart for presence,
+//so every instance sharing the Redis sees the same events and viewers.
+//Channels and keys are put under keyPrefix when it's not empty.
+func NewRedisBroker(logger *logrus.Logger, client redis.UniversalClient, keyPrefix string) Broker {
+	return &redisBroker{
+		client:    client,
+		logger:    logger,
+		keyPrefix: keyPrefix,
+	}
+}
+
+//key gives the channel or presence key of a cart, "[<prefix>:]cart_events:<tenant>:<cart>"
+func (b *redisBroker) key(ctx context.Context, kind, cartID string) string {
+	key := kind + topic(ctx, cartID)
+	if b.keyPrefix != "" {
+		key = b.keyPrefix + ":" + key
+	}
+	return key
+}
+
+func (b *redisBroker) Publish(ctx context.Context, event Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return err
+	}
+	err = b.client.Publish(ctx, b.key(ctx, channelPrefix, event.CartID), payload).Err()
+	if err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return err
+	}
+	return nil
+}
+
+func (b *redisBroker) Subscribe(ctx context.Context, cartID string) (<-chan Event, error) {
+	pubsub := b.client.Subscribe(ctx, b.key(ctx, channelPrefix, cartID))
+	//Receive waits for the subscription to be confirmed
+	if _, err := pubsub.Receive(ctx); err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		pubsub.Close()
+		return nil, err
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	go func() {
+		defer close(ch)
+		defer pubsub.Close()
+		messages := pubsub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				event := Event{}
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+					continue
+				}
+				select {
+				case ch <- event:
+				default:
+					//slow subscriber, it missed the event and has to start over
+					b.logger.WithContext(ctx).WithField("cart_id", cartID).Warn("collab_subscriber_behind")
+					return
+				}
+			}
+		}
+	}()
+
+	return ch, nil
+}
+
+func (b *redisBroker) Join(ctx context.Context, cartID, viewer string) ([]string, error) {
+	key := b.key(ctx, presencePrefix, cartID)
+	pipe := b.client.TxPipeline()
+	pipe.HIncrBy(ctx, key, viewer, 1)
+	pipe.Expire(ctx, key, presenceTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return nil, err
+	}
+	return b.viewers(ctx, key)
+}
+
+func (b *redisBroker) Refresh(ctx context.Context, cartID string) error {
+	if err := b.client.Expire(ctx, b.key(ctx, presencePrefix, cartID), presenceTTL).Err(); err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return err
+	}
+	return nil
+}
+
+func (b *redisBroker) Leave(ctx context.Context, cartID, viewer string) ([]string, error) {
+	key := b.key(ctx, presencePrefix, cartID)
+	count, err := b.client.HIncrBy(ctx, key, viewer, -1).Result()
+	if err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return nil, err
+	}
+	if count <= 0 {
+		if err := b.client.HDel(ctx, key, viewer).Err(); err != nil {
+			b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+			return nil, err
+		}
+	}
+	return b.viewers(ctx, key)
+}
+
+func (b *redisBroker) viewers(ctx context.Context, key string) ([]string, error) {
+	viewers, err := b.client.HKeys(ctx, key).Result()
+	if err != nil {
+		b.logger.WithContext(ctx).WithError(err).Error("collab_error")
+		return nil, err
+	}
+	sort.Strings(viewers)
+	return viewers, nil
+}
@@ -0,0 +1,79 @@
+package collab_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/go-redis/redismock/v8"
+	"github.com/sirupsen/logrus"
+)
+
+var testLogger = logrus.New()
+
+func TestRedisPublishOK(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	ev := collab.Event{Type: collab.EventPresence, CartID: "cart1", Viewers: []string{"bob"}}
+	b, _ := json.Marshal(ev)
+	mock.ExpectPublish("cart_events:default:cart1", b).SetVal(1)
+
+	if collab.NewRedisBroker(testLogger, db, "").Publish(context.TODO(), ev) != nil {
+		t.Fatalf("Error was not expected")
+	}
+}
+
+func TestRedisPublishError(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	ev := collab.Event{Type: collab.EventPresence, CartID: "cart1"}
+	b, _ := json.Marshal(ev)
+	mock.ExpectPublish("cart_events:default:cart1", b).SetErr(fmt.Errorf("mocked error"))
+
+	if collab.NewRedisBroker(testLogger, db, "").Publish(context.TODO(), ev) == nil {
+		t.Fatalf("Error was expected")
+	}
+}
+
+func TestRedisLeaveOK(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mock.ExpectHIncrBy("cart_presence:default:cart1", "bob", -1).SetVal(0)
+	mock.ExpectHDel("cart_presence:default:cart1", "bob").SetVal(1)
+	mock.ExpectHKeys("cart_presence:default:cart1").SetVal([]string{"bob2", "alice"})
+
+	viewers, err := collab.NewRedisBroker(testLogger, db, "").Leave(context.TODO(), "cart1", "bob")
+	if err != nil {
+		t.Fatalf("Error was not expected")
+	}
+	if len(viewers) != 2 || viewers[0] != "alice" {
+		t.Fatalf("Unexpected viewers: %v", viewers)
+	}
+}
+
+func TestRedisLeaveError(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mock.ExpectHIncrBy("cart_presence:default:cart1", "bob", -1).SetErr(fmt.Errorf("mocked error"))
+
+	if _, err := collab.NewRedisBroker(testLogger, db, "").Leave(context.TODO(), "cart1", "bob"); err == nil {
+		t.Fatalf("Error was expected")
+	}
+}
+
+func TestRedisRefreshOK(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mock.ExpectExpire("cart_presence:default:cart1", 5*time.Minute).SetVal(true)
+
+	if collab.NewRedisBroker(testLogger, db, "").Refresh(context.TODO(), "cart1") != nil {
+		t.Fatalf("Error was not expected")
+	}
+}
+
+func TestRedisRefreshError(t *testing.T) {
+	db, mock := redismock.NewClientMock()
+	mock.ExpectExpire("cart_presence:default:cart1", 5*time.Minute).SetErr(fmt.Errorf("mocked error"))
+
+	if collab.NewRedisBroker(testLogger, db, "").Refresh(context.TODO(), "cart1") == nil {
+		t.Fatalf("Error was expected")
+	}
+}
@@ -7,12 +7,27 @@ const (
 	ItemAlreadyInCartCode      = "err_item_already_in_cart"
 	ExternalApiErrorCode       = "err_external_api_error"
 	CacheErrorCode             = "err_cache"
+
+	BadRequestCode = "err_bad_request"
+	InternalCode   = "err_internal"
 )
 
+//ServiceError is an error with a code clients can rely on.
+//The Cause is there for logging, it never reaches clients.
 type ServiceError struct {
-	Code string
+	Code  string
+	Cause error
+}
+
+//Wrap gives a ServiceError with code caused by cause
+func Wrap(code string, cause error) ServiceError {
+	return ServiceError{Code: code, Cause: cause}
 }
 
 func (s ServiceError) Error() string {
 	return s.Code
 }
+
+func (s ServiceError) Unwrap() error {
+	return s.Cause
+}
@@ -1,6 +1,7 @@
 package errors_test
 
 import (
+	stderrors "errors"
 	"testing"
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
@@ -15,3 +16,15 @@ func TestErrorCode(t *testing.T) {
 		t.Fatalf("Error code unexpected")
 	}
 }
+
+func TestWrapKeepsCause(t *testing.T) {
+	cause := stderrors.New("connection refused")
+	err := errors.Wrap(errors.CacheErrorCode, cause)
+
+	if err.Error() != errors.CacheErrorCode {
+		t.Fatalf("The cause was not expected in the error message")
+	}
+	if !stderrors.Is(err, cause) {
+		t.Fatalf("The cause was expected to be unwrapped")
+	}
+}
@@ -0,0 +1,52 @@
+package errors
+
+import (
+	"net/http"
+	"sort"
+)
+
+//Definition is what an error code means to clients and operators
+type Definition struct {
+	Code string
+	//Status is the HTTP status the error is answered with
+	Status      int
+	Description string
+	//Retryable errors may go away when the request is retried
+	Retryable bool
+}
+
+//definitions are every error code, by code
+var definitions = map[string]Definition{}
+
+func init() {
+	for _, d := range []Definition{
+		{Code: InternalCode, Status: http.StatusInternalServerError, Description: "Internal Server Error"},
+		{Code: BadRequestCode, Status: http.StatusBadRequest, Description: "The request contains errors"},
+		{Code: CartNotFoundCode, Status: http.StatusNotFound, Description: "The Cart ID was not found"},
+		{Code: ItemNotFoundCode, Status: http.StatusNotFound, Description: "The item does not exists in the cart"},
+		{Code: ItemNotFoundOnProviderCode, Status: http.StatusNotFound, Description: "The item was not found on the provider"},
+		{Code: ItemAlreadyInCartCode, Status: http.StatusUnprocessableEntity, Description: "The item already exists in the cart"},
+		{Code: ExternalApiErrorCode, Status: http.StatusInternalServerError, Description: "The items provider could not be reached", Retryable: true},
+		{Code: CacheErrorCode, Status: http.StatusInternalServerError, Description: "The cart could not be stored", Retryable: true},
+	} {
+		definitions[d.Code] = d
+	}
+}
+
+//Lookup gives the definition of code, unknown codes are internal errors
+func Lookup(code string) Definition {
+	if d, ok := definitions[code]; ok {
+		return d
+	}
+	return definitions[InternalCode]
+}
+
+//Definitions are every error code, sorted by code
+func Definitions() []Definition {
+	list := make([]Definition, 0, len(definitions))
+	for _, d := range definitions {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Code < list[j].Code })
+	return list
+}
@@ -0,0 +1,46 @@
+package errors_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+)
+
+var allCodes = []string{
+	errors.CartNotFoundCode,
+	errors.ItemNotFoundCode,
+	errors.ItemNotFoundOnProviderCode,
+	errors.ItemAlreadyInCartCode,
+	errors.ExternalApiErrorCode,
+	errors.CacheErrorCode,
+	errors.BadRequestCode,
+	errors.InternalCode,
+}
+
+func TestEveryCodeRegistered(t *testing.T) {
+	if len(errors.Definitions()) != len(allCodes) {
+		t.Fatalf("Unexpected definitions %v", errors.Definitions())
+	}
+	for _, code := range allCodes {
+		d := errors.Lookup(code)
+		if d.Code != code {
+			t.Fatalf("Code %s is not registered", code)
+		}
+		if d.Status == 0 || d.Description == "" {
+			t.Fatalf("Code %s needs a status and a description", code)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	if d := errors.Lookup(errors.ItemAlreadyInCartCode); d.Status != http.StatusUnprocessableEntity || d.Retryable {
+		t.Fatalf("Unexpected definition %+v", d)
+	}
+	if d := errors.Lookup(errors.ExternalApiErrorCode); !d.Retryable {
+		t.Fatalf("Unexpected definition %+v", d)
+	}
+	if d := errors.Lookup("err_unknown"); d.Code != errors.InternalCode || d.Status != http.StatusInternalServerError {
+		t.Fatalf("Unknown codes were expected to be internal errors %+v", d)
+	}
+}
@@ -1,34 +1,231 @@
 package health
 
 import (
-	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
-	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//Status of a dependency, or of the whole service
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded"
+	StatusDown     = "down"
 )
 
+//Names of the dependencies checked by the service
+const (
+	CheckCache       = "cache"
+	CheckExternalAPI = "external api"
+	CheckCartStorage = "cart storage"
+)
+
+//Result is the state of a dependency
+type Result struct {
+	Name string
+	//Critical dependencies being down make the service not ready
+	Critical bool
+	Status   string
+	//Latency of the last check
+	Latency time.Duration
+	//LastError is empty when the dependency never failed
+	LastError     string
+	LastErrorAt   time.Time
+	LastSuccessAt time.Time
+	//Since is when the dependency got its status
+	Since time.Time
+}
+
+//Report is the state of every dependency
+type Report struct {
+	//Status is down when a critical dependency is down, degraded when any dependency is not up
+	Status  string
+	Results []Result
+	//CheckedAt is when the checks ran, reports are cached
+	CheckedAt time.Time
+}
+
+//Ready tells whether the service can take traffic: no critical dependency is down
+func (r Report) Ready() bool {
+	return r.Status != StatusDown
+}
+
 //Service is the interface for the health
 type Service interface {
-	HealthCheck() (service bool, externalAPI bool, cache bool, err error)
+	//Check checks every registered dependency
+	Check(ctx context.Context) Report
+	//History are the last status transitions of the dependencies, oldest first
+	History() []Transition
 }
+
 type svc struct {
-	cache           cache.Cache
-	externalService item.ExternalService
+	registry        *Registry
+	timeout         time.Duration
+	degradedLatency time.Duration
+	cacheTTL        time.Duration
+	logger          *logrus.Logger
+
+	//mu is held while checking, so concurrent probes wait for one run instead of starting their own
+	mu      sync.Mutex
+	state   map[string]Result
+	report  Report
+	history *history
+}
+
+//Option customizes the health service
+type Option func(*svc)
+
+//WithTimeout is the deadline of a whole run, a check still running by then is down. 2 seconds by default.
+func WithTimeout(timeout time.Duration) Option {
+	return func(s *svc) {
+		s.timeout = timeout
+	}
+}
+
+//WithDegradedLatency is the latency above which a dependency that answered is degraded. 500ms by default.
+func WithDegradedLatency(latency time.Duration) Option {
+	return func(s *svc) {
+		s.degradedLatency = latency
+	}
 }
 
-//NewService gives a new Service
-func NewService(c cache.Cache, es item.ExternalService) Service {
-	return &svc{
-		cache:           c,
-		externalService: es,
+//WithCacheTTL is how long a report is served before checking again, so probes can't overload
+//the dependencies. 1 second by default, zero checks on every call.
+func WithCacheTTL(ttl time.Duration) Option {
+	return func(s *svc) {
+		s.cacheTTL = ttl
 	}
 }
 
-//HealthCheck returns the status of the API and it's components
-func (s *svc) HealthCheck() (service bool, externalAPI bool, cache bool, err error) {
-	externalApiHealth := true
+//WithHistorySize is how many transitions History keeps. 100 by default.
+func WithHistorySize(size int) Option {
+	return func(s *svc) {
+		s.history = newHistory(size)
+	}
+}
 
-	exterr := s.externalService.Health()
-	if exterr != nil {
-		externalApiHealth = false
+//WithLogger is where transitions are logged
+func WithLogger(logger *logrus.Logger) Option {
+	return func(s *svc) {
+		s.logger = logger
+	}
+}
+
+//NewService gives a new Service checking the dependencies in registry
+func NewService(registry *Registry, opts ...Option) Service {
+	s := &svc{
+		registry:        registry,
+		timeout:         2 * time.Second,
+		degradedLatency: 500 * time.Millisecond,
+		cacheTTL:        time.Second,
+		logger:          logrus.StandardLogger(),
+		state:           map[string]Result{},
+		history:         newHistory(100),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+//outcome is a single run of a check
+type outcome struct {
+	start   time.Time
+	latency time.Duration
+	err     error
+}
+
+//Check runs the checks concurrently and returns the state of every dependency
+func (s *svc) Check(_ context.Context) Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.report.CheckedAt.IsZero() && time.Since(s.report.CheckedAt) < s.cacheTTL {
+		return s.report
+	}
+
+	//checks are not bound to the caller, a probe giving up must not leave the cached report with errors
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	checks := s.registry.Checks()
+	outcomes := make([]outcome, len(checks))
+	wg := sync.WaitGroup{}
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c Check) {
+			defer wg.Done()
+			outcomes[i] = run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusUp, CheckedAt: time.Now()}
+	for i, c := range checks {
+		r := s.record(c, outcomes[i])
+		switch {
+		case r.Status == StatusDown && r.Critical:
+			report.Status = StatusDown
+		case r.Status != StatusUp && report.Status == StatusUp:
+			report.Status = StatusDegraded
+		}
+		report.Results = append(report.Results, r)
+	}
+	s.report = report
+	return report
+}
+
+//run runs a check within its own timeout
+func run(ctx context.Context, c Check) outcome {
+	if c.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	errc := make(chan error, 1)
+	go func() {
+		errc <- c.Run(ctx)
+	}()
+	//a check ignoring its context is down all the same when its time is up
+	select {
+	case err := <-errc:
+		return outcome{start: start, latency: time.Since(start), err: err}
+	case <-ctx.Done():
+		return outcome{start: start, latency: time.Since(start), err: ctx.Err()}
+	}
+}
+
+//History are the last status transitions of the dependencies, oldest first
+func (s *svc) History() []Transition {
+	return s.history.list()
+}
+
+//record keeps the outcome of a check, so the last error and last success are remembered
+func (s *svc) record(c Check, o outcome) Result {
+	previous := s.state[c.Name]
+	r := previous
+	r.Name = c.Name
+	r.Critical = c.Critical
+	r.Latency = o.latency
+	switch {
+	case o.err != nil:
+		r.Status = StatusDown
+		r.LastError = o.err.Error()
+		r.LastErrorAt = o.start
+	case o.latency > s.degradedLatency:
+		r.Status = StatusDegraded
+		r.LastSuccessAt = o.start
+	default:
+		r.Status = StatusUp
+		r.LastSuccessAt = o.start
+	}
+	if r.Status != previous.Status {
+		r.Since = o.start
+		s.transition(previous, r, o.err)
 	}
-	return true, externalApiHealth, s.cache.Alive(), nil
+	s.state[c.Name] = r
+	return r
 }
@@ -1,116 +1,154 @@
 package health
 
 import (
+	"context"
 	"fmt"
+	"sync/atomic"
 	"testing"
+	"time"
 
-	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	logtest "github.com/sirupsen/logrus/hooks/test"
 )
 
+var testLogger, _ = logtest.NewNullLogger()
+
+//newService registers the cache and the external API the way main does
+func newService(c *cacheMocked, e *externalAPIMocked, opts ...Option) Service {
+	registry := NewRegistry()
+	registry.MustRegister(
+		Check{Name: CheckCache, Critical: true, Run: AliveCheck(c.Alive)},
+		Check{Name: CheckExternalAPI, Run: e.Health},
+	)
+	return NewService(registry, append([]Option{WithLogger(testLogger)}, opts...)...)
+}
+
 func TestHealthCheck(t *testing.T) {
-	service := NewService(
+	service := newService(
 		&cacheMocked{cacheShouldFail: false},
 		&externalAPIMocked{externalAPIShouldFail: false},
 	)
 
-	s, e, d, err := service.HealthCheck()
-	if s != true || e != true || d != true || err != nil {
-		t.Errorf("Unexpected values from method: service %t, external %t, db %t, error %s", s, e, d, err)
+	report := service.Check(context.TODO())
+	if report.Status != StatusUp || !report.Ready() {
+		t.Fatalf("Unexpected status %s", report.Status)
+	}
+	if len(report.Results) != 2 {
+		t.Fatalf("Unexpected results %v", report.Results)
+	}
+	for _, r := range report.Results {
+		if r.Status != StatusUp || r.LastSuccessAt.IsZero() || r.LastError != "" {
+			t.Fatalf("Unexpected result %+v", r)
+		}
 	}
 }
 
 func TestHealthCheck_CacheFail(t *testing.T) {
-	service := NewService(
+	service := newService(
 		&cacheMocked{cacheShouldFail: true},
 		&externalAPIMocked{externalAPIShouldFail: false},
 	)
 
-	s, e, d, err := service.HealthCheck()
-	if s != true || e != true || d != false || err != nil {
-		t.Errorf("Unexpected values from method: service %t, external %t, db %t, error %s", s, e, d, err)
+	report := service.Check(context.TODO())
+	if report.Status != StatusDown || report.Ready() {
+		t.Fatalf("A critical dependency down was expected to make the service not ready")
+	}
+	if r := report.Results[0]; r.Name != CheckCache || r.Status != StatusDown || r.LastError == "" {
+		t.Fatalf("Unexpected result %+v", r)
 	}
 }
 
 func TestHealthCheck_ExternalFail(t *testing.T) {
-	service := NewService(
+	service := newService(
 		&cacheMocked{cacheShouldFail: false},
 		&externalAPIMocked{externalAPIShouldFail: true},
 	)
 
-	s, e, d, err := service.HealthCheck()
-	if s != true || e != false || d != true || err != nil {
-		t.Errorf("Unexpected values from method: service %t, external %t, db %t, error %s", s, e, d, err)
+	report := service.Check(context.TODO())
+	if report.Status != StatusDegraded || !report.Ready() {
+		t.Fatalf("A non critical dependency down was expected to degrade the service")
+	}
+	if r := report.Results[1]; r.Name != CheckExternalAPI || r.Status != StatusDown || r.LastError != "External API Mock was asked to fail" {
+		t.Fatalf("Unexpected result %+v", r)
 	}
 }
 
-//Cache Mocked
+func TestHealthCheck_Slow(t *testing.T) {
+	service := newService(
+		&cacheMocked{cacheShouldFail: false},
+		&externalAPIMocked{delay: 20 * time.Millisecond},
+		WithDegradedLatency(10*time.Millisecond),
+	)
 
-type cacheMocked struct {
-	cacheShouldFail bool
+	report := service.Check(context.TODO())
+	if report.Status != StatusDegraded {
+		t.Fatalf("Unexpected status %s", report.Status)
+	}
+	if r := report.Results[1]; r.Status != StatusDegraded || r.Latency < 20*time.Millisecond {
+		t.Fatalf("Unexpected result %+v", r)
+	}
 }
 
-func (c *cacheMocked) Set(key string, value interface{}) error {
-	if c.cacheShouldFail {
-		return fmt.Errorf("Mock Cache Asked to Fail")
+func TestHealthCheck_Timeout(t *testing.T) {
+	service := newService(
+		&cacheMocked{cacheShouldFail: false},
+		&externalAPIMocked{delay: time.Second},
+		WithTimeout(10*time.Millisecond),
+	)
+
+	if r := service.Check(context.TODO()).Results[1]; r.Status != StatusDown || r.Latency >= time.Second {
+		t.Fatalf("A check was expected to be down on timeout %+v", r)
 	}
-	return nil
 }
-func (c *cacheMocked) Get(key string, here interface{}) error {
-	if c.cacheShouldFail {
-		return fmt.Errorf("Mock Cache Asked to Fail")
+
+func TestHealthCheck_RemembersLastOutcomes(t *testing.T) {
+	external := &externalAPIMocked{externalAPIShouldFail: false}
+	service := newService(&cacheMocked{cacheShouldFail: false}, external, WithCacheTTL(0))
+
+	succeeded := service.Check(context.TODO()).Results[1]
+	external.externalAPIShouldFail = true
+	failed := service.Check(context.TODO()).Results[1]
+
+	if failed.LastSuccessAt != succeeded.LastSuccessAt {
+		t.Fatalf("Last success was expected to be kept")
 	}
-	return nil
-}
-func (c *cacheMocked) Del(key string) error {
-	if c.cacheShouldFail {
-		return fmt.Errorf("Mock Cache Asked to Fail")
+	if failed.LastError == "" || failed.LastErrorAt.Before(failed.LastSuccessAt) {
+		t.Fatalf("Unexpected result %+v", failed)
 	}
-	return nil
-}
-func (c *cacheMocked) Alive() bool {
-	if c.cacheShouldFail {
-		return false
+
+	external.externalAPIShouldFail = false
+	recovered := service.Check(context.TODO()).Results[1]
+	if recovered.Status != StatusUp || recovered.LastError != failed.LastError {
+		t.Fatalf("Last error was expected to be kept after recovering %+v", recovered)
 	}
-	return true
+}
+
+//Cache Mocked
+
+type cacheMocked struct {
+	cacheShouldFail bool
+}
+
+func (c *cacheMocked) Alive(ctx context.Context) bool {
+	return !c.cacheShouldFail
 }
 
 //External API Mocked
 
 type externalAPIMocked struct {
 	externalAPIShouldFail bool
+	delay                 time.Duration
+	calls                 int32
 }
 
-func (e *externalAPIMocked) Health() error {
+func (e *externalAPIMocked) Health(ctx context.Context) error {
+	atomic.AddInt32(&e.calls, 1)
+	select {
+	case <-time.After(e.delay):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
 	if e.externalAPIShouldFail {
 		return fmt.Errorf("External API Mock was asked to fail")
 	}
 	return nil
 }
-
-func (e *externalAPIMocked) GetItem(id string) (models.Item, error) {
-	if e.externalAPIShouldFail {
-		return models.Item{}, fmt.Errorf("External API Mock was asked to fail")
-	}
-	return models.Item{
-		ID:    "mockedItem",
-		Name:  "Mocked Item",
-		Price: 999.99,
-	}, nil
-}
-func (e *externalAPIMocked) GetAllItems() ([]models.Item, error) {
-	if e.externalAPIShouldFail {
-		return []models.Item{}, fmt.Errorf("External API Mock was asked to fail")
-	}
-	return []models.Item{
-		{
-			ID:    "mockedItem1",
-			Name:  "Mocked Item 1",
-			Price: 999.99,
-		},
-		{
-			ID:    "mockedItem2",
-			Name:  "Mocked Item 2",
-			Price: 999.99,
-		},
-	}, nil
-}
@@ -0,0 +1,110 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+//Transition is a dependency changing status
+type Transition struct {
+	Name string
+	//From is empty the first time the dependency is checked
+	From string
+	To   string
+	At   time.Time
+	//Duration is how long the dependency was in From, how long an outage lasted when going up
+	Duration time.Duration
+	//Error made the dependency go down
+	Error string
+}
+
+//history is a ring buffer of transitions. It has its own lock, so reading it never waits for a run of the checks.
+type history struct {
+	mu          sync.Mutex
+	transitions []Transition
+	next        int
+	full        bool
+}
+
+func newHistory(size int) *history {
+	if size < 1 {
+		size = 1
+	}
+	return &history{transitions: make([]Transition, size)}
+}
+
+func (h *history) add(t Transition) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.transitions[h.next] = t
+	h.next = (h.next + 1) % len(h.transitions)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+//list gives the transitions oldest first
+func (h *history) list() []Transition {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.full {
+		return append([]Transition{}, h.transitions[:h.next]...)
+	}
+	return append(append([]Transition{}, h.transitions[h.next:]...), h.transitions[:h.next]...)
+}
+
+//transition records and logs a dependency going from previous to current
+func (s *svc) transition(previous, current Result, err error) {
+	t := Transition{
+		Name: current.Name,
+		From: previous.Status,
+		To:   current.Status,
+		At:   current.Since,
+	}
+	if !previous.Since.IsZero() {
+		t.Duration = current.Since.Sub(previous.Since)
+	}
+	if err != nil {
+		t.Error = err.Error()
+	}
+	s.history.add(t)
+
+	level := logrus.InfoLevel
+	switch {
+	case t.To == StatusDown:
+		level = logrus.ErrorLevel
+	case t.To == StatusDegraded:
+		level = logrus.WarnLevel
+	}
+	entry := s.logger.WithFields(logrus.Fields{
+		"dependency": t.Name,
+		"from":       t.From,
+		"to":         t.To,
+		"critical":   current.Critical,
+	})
+	if t.From != "" {
+		entry = entry.WithField("duration_ms", t.Duration.Milliseconds())
+	}
+	if err != nil {
+		entry = entry.WithError(err)
+	}
+	entry.Log(level, "health_transition")
+}
+
+//Monitor checks the dependencies every interval until ctx is done, so transitions are recorded
+//even when nobody asks for the health. Run it in its own goroutine.
+func Monitor(ctx context.Context, service Service, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		service.Check(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+)
+
+func TestTransitionsRecorded(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	external := &externalAPIMocked{}
+	service := newService(&cacheMocked{}, external, WithCacheTTL(0), WithLogger(logger))
+
+	service.Check(context.TODO())
+	external.externalAPIShouldFail = true
+	service.Check(context.TODO())
+	service.Check(context.TODO())
+	external.externalAPIShouldFail = false
+	service.Check(context.TODO())
+
+	history := service.History()
+	if len(history) != 4 {
+		t.Fatalf("Unexpected transitions %+v", history)
+	}
+	if history[0].Name != CheckCache || history[0].From != "" || history[0].To != StatusUp {
+		t.Fatalf("The first check was expected to be recorded %+v", history[0])
+	}
+	down, up := history[2], history[3]
+	if down.Name != CheckExternalAPI || down.From != StatusUp || down.To != StatusDown || down.Error == "" {
+		t.Fatalf("Unexpected transition %+v", down)
+	}
+	if up.From != StatusDown || up.To != StatusUp || up.Duration != up.At.Sub(down.At) {
+		t.Fatalf("The outage was expected to be measured %+v", up)
+	}
+
+	entries := hook.AllEntries()
+	if len(entries) != 4 || entries[2].Level != logrus.ErrorLevel || entries[2].Data["dependency"] != CheckExternalAPI || entries[2].Data["to"] != StatusDown {
+		t.Fatalf("Transitions were expected to be logged")
+	}
+	if entries[3].Level != logrus.InfoLevel || entries[3].Data["duration_ms"] == nil {
+		t.Fatalf("Unexpected log %+v", entries[3].Data)
+	}
+}
+
+func TestHistoryIsBounded(t *testing.T) {
+	logger, _ := logtest.NewNullLogger()
+	external := &externalAPIMocked{}
+	service := newService(&cacheMocked{}, external, WithCacheTTL(0), WithLogger(logger), WithHistorySize(3))
+
+	for i := 0; i < 5; i++ {
+		external.externalAPIShouldFail = i%2 == 1
+		service.Check(context.TODO())
+	}
+
+	history := service.History()
+	if len(history) != 3 {
+		t.Fatalf("Unexpected transitions %+v", history)
+	}
+	for i, to := range []string{StatusUp, StatusDown, StatusUp} {
+		if history[i].Name != CheckExternalAPI || history[i].To != to {
+			t.Fatalf("The last transitions were expected oldest first %+v", history)
+		}
+	}
+	if !history[0].At.Before(history[2].At) {
+		t.Fatalf("Unexpected order %+v", history)
+	}
+}
+
+func TestHistoryDoesNotWaitForChecks(t *testing.T) {
+	registry := NewRegistry()
+	release := make(chan struct{})
+	registry.MustRegister(Check{Name: "slow", Run: func(ctx context.Context) error {
+		<-release
+		return nil
+	}})
+	service := NewService(registry, WithLogger(testLogger))
+	go service.Check(context.TODO())
+	defer close(release)
+	time.Sleep(10 * time.Millisecond)
+
+	done := make(chan struct{})
+	go func() {
+		service.History()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("History was not expected to wait for the checks")
+	}
+}
+
+func TestMonitor(t *testing.T) {
+	logger, _ := logtest.NewNullLogger()
+	external := &externalAPIMocked{}
+	service := newService(&cacheMocked{}, external, WithCacheTTL(0), WithLogger(logger))
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		Monitor(ctx, service, time.Millisecond)
+		close(done)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	<-done
+
+	if calls := atomic.LoadInt32(&external.calls); calls < 2 {
+		t.Fatalf("Dependencies were expected to be polled, got %d checks", calls)
+	}
+	if len(service.History()) != 2 {
+		t.Fatalf("Unexpected transitions %+v", service.History())
+	}
+}
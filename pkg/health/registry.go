@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//errNotAlive is the error of an AliveCheck that failed, the dependency does not tell why
+var errNotAlive = errors.New("dependency is not alive")
+
+//Check is a dependency of the service
+type Check struct {
+	Name string
+	//Critical dependencies being down make the service not ready
+	Critical bool
+	//Timeout of the check, bounded by the timeout of the service. The timeout of the service when zero.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+}
+
+//AliveCheck adapts the Alive method most components have
+func AliveCheck(alive func(ctx context.Context) bool) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		if !alive(ctx) {
+			return errNotAlive
+		}
+		return nil
+	}
+}
+
+//Registry holds the checks of the service, components register their own
+type Registry struct {
+	mu     sync.Mutex
+	checks []Check
+}
+
+//NewRegistry gives an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+//Register adds a check, names are unique
+func (r *Registry) Register(c Check) error {
+	if c.Name == "" || c.Run == nil {
+		return errors.New("health check needs a name and a run function")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, registered := range r.checks {
+		if registered.Name == c.Name {
+			return fmt.Errorf("health check %q is already registered", c.Name)
+		}
+	}
+	r.checks = append(r.checks, c)
+	return nil
+}
+
+//MustRegister is Register panicking on error, for checks registered at startup
+func (r *Registry) MustRegister(checks ...Check) {
+	for _, c := range checks {
+		if err := r.Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+//Checks are the registered checks, in registration order
+func (r *Registry) Checks() []Check {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Check{}, r.checks...)
+}
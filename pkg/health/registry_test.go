@@ -0,0 +1,97 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRegisterRejectsDuplicates(t *testing.T) {
+	registry := NewRegistry()
+	if err := registry.Register(Check{Name: "db", Run: func(ctx context.Context) error { return nil }}); err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if err := registry.Register(Check{Name: "db", Run: func(ctx context.Context) error { return nil }}); err == nil {
+		t.Fatalf("Error was expected for a repeated name")
+	}
+	if err := registry.Register(Check{Name: "queue"}); err == nil {
+		t.Fatalf("Error was expected for a check without run function")
+	}
+	if len(registry.Checks()) != 1 {
+		t.Fatalf("Unexpected checks %v", registry.Checks())
+	}
+}
+
+func TestRegisteredLater(t *testing.T) {
+	registry := NewRegistry()
+	service := NewService(registry, WithCacheTTL(0), WithLogger(testLogger))
+	registry.MustRegister(Check{Name: "queue", Critical: true, Run: func(ctx context.Context) error { return errors.New("no broker") }})
+
+	if report := service.Check(context.TODO()); report.Ready() || report.Results[0].Name != "queue" {
+		t.Fatalf("Checks registered after the service was created were expected to run %+v", report)
+	}
+}
+
+func TestChecksRunConcurrently(t *testing.T) {
+	registry := NewRegistry()
+	for _, name := range []string{"a", "b", "c"} {
+		registry.MustRegister(Check{Name: name, Run: func(ctx context.Context) error {
+			time.Sleep(50 * time.Millisecond)
+			return nil
+		}})
+	}
+
+	start := time.Now()
+	NewService(registry, WithLogger(testLogger)).Check(context.TODO())
+	if time.Since(start) >= 150*time.Millisecond {
+		t.Fatalf("Checks were expected to run concurrently")
+	}
+}
+
+func TestCheckTimeouts(t *testing.T) {
+	registry := NewRegistry()
+	//ignores its context, it is down all the same
+	registry.MustRegister(Check{Name: "stuck", Timeout: 10 * time.Millisecond, Run: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}})
+	registry.MustRegister(Check{Name: "slow", Run: func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}})
+
+	start := time.Now()
+	report := NewService(registry, WithTimeout(50*time.Millisecond), WithLogger(testLogger)).Check(context.TODO())
+	if time.Since(start) >= time.Second {
+		t.Fatalf("The overall deadline was expected to bound the run")
+	}
+	for _, r := range report.Results {
+		if r.Status != StatusDown {
+			t.Fatalf("Unexpected result %+v", r)
+		}
+	}
+	if report.Results[0].Latency >= 50*time.Millisecond {
+		t.Fatalf("The timeout of the check was expected %+v", report.Results[0])
+	}
+}
+
+func TestReportCached(t *testing.T) {
+	external := &externalAPIMocked{}
+	service := newService(&cacheMocked{}, external, WithCacheTTL(time.Hour))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			service.Check(context.TODO())
+		}()
+	}
+	wg.Wait()
+	if calls := atomic.LoadInt32(&external.calls); calls != 1 {
+		t.Fatalf("The report was expected to be cached, got %d checks", calls)
+	}
+}
@@ -1,6 +1,7 @@
 package item
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
 
 	"github.com/sirupsen/logrus"
@@ -21,9 +23,9 @@ const (
 )
 
 type ExternalService interface {
-	Health() error
-	GetItem(id string) (models.Item, error)
-	GetAllItems() ([]models.Item, error)
+	Health(ctx context.Context) error
+	GetItem(ctx context.Context, id string) (models.Item, error)
+	GetAllItems(ctx context.Context) ([]models.Item, error)
 }
 
 type externalService struct {
@@ -32,7 +34,7 @@ type externalService struct {
 }
 
 type ItemClient interface {
-	Get(url string) (resp *http.Response, err error)
+	Do(req *http.Request) (resp *http.Response, err error)
 }
 
 func NewExternalService(logger *logrus.Logger, client ItemClient) ExternalService {
@@ -43,35 +45,45 @@ func NewExternalService(logger *logrus.Logger, client ItemClient) ExternalServic
 	}
 }
 
-func (e *externalService) Health() error {
-	e.logger.Log(logrus.InfoLevel, "Calling External API Health")
-	res, err := e.client.Get(healthEndpoint)
+func (e *externalService) Health(ctx context.Context) error {
+	logger := e.logger.WithContext(ctx)
+	logger.Log(logrus.InfoLevel, "Calling External API Health")
+	res, err := e.get(ctx, healthEndpoint)
 	if err != nil {
-		e.logger.WithError(err).Log(logrus.ErrorLevel, "Error Calling External API Health")
+		logger.WithError(err).Log(logrus.ErrorLevel, "Error Calling External API Health")
+		return err
+	}
+	defer res.Body.Close()
+	if err := statusError(res); err != nil {
+		logger.WithError(err).Log(logrus.ErrorLevel, "External API Not Healthy")
 		return err
 	}
 	eHealth := viewmodels.ExternalHealthResponse{}
 
 	err = json.NewDecoder(res.Body).Decode(&eHealth)
 	if err != nil {
-		e.logger.WithError(err).Log(logrus.ErrorLevel, "Error Decoding External API Health")
+		logger.WithError(err).Log(logrus.ErrorLevel, "Error Decoding External API Health")
 		return err
 	}
 
 	if eHealth.Data.Status != healthStatusOK {
-		e.logger.WithField("external_api_status", eHealth.Data.Status).Log(logrus.ErrorLevel, "External API Not Healthy")
+		logger.WithField("external_api_status", eHealth.Data.Status).Log(logrus.ErrorLevel, "External API Not Healthy")
 		return fmt.Errorf("external API not Healthy - Status: %s", eHealth.Data.Status)
 	}
 	return nil
 }
-func (e *externalService) GetItem(id string) (models.Item, error) {
-	res, err := e.client.Get(articlesEndpoint + "/" + id)
+func (e *externalService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	res, err := e.get(ctx, articlesEndpoint+"/"+id)
 	if err != nil {
 		return models.Item{}, err
 	}
+	defer res.Body.Close()
 	if res.StatusCode == http.StatusNotFound {
 		return models.Item{}, errors.ServiceError{Code: errors.ItemNotFoundOnProviderCode}
 	}
+	if err := statusError(res); err != nil {
+		return models.Item{}, err
+	}
 	eItem := viewmodels.ExternalGetItemResponse{}
 
 	err = json.NewDecoder(res.Body).Decode(&eItem)
@@ -91,11 +103,15 @@ func (e *externalService) GetItem(id string) (models.Item, error) {
 
 	return mItem, nil
 }
-func (e *externalService) GetAllItems() ([]models.Item, error) {
-	res, err := e.client.Get(articlesEndpoint)
+func (e *externalService) GetAllItems(ctx context.Context) ([]models.Item, error) {
+	res, err := e.get(ctx, articlesEndpoint)
 	if err != nil {
 		return []models.Item{}, err
 	}
+	defer res.Body.Close()
+	if err := statusError(res); err != nil {
+		return []models.Item{}, err
+	}
 	eItems := viewmodels.ExternalGetAllItemsResponse{}
 
 	err = json.NewDecoder(res.Body).Decode(&eItems)
@@ -118,3 +134,23 @@ func (e *externalService) GetAllItems() ([]models.Item, error) {
 
 	return mItems, nil
 }
+
+//statusError fails the responses of the provider that are not a success, their body is not what we expect
+func statusError(res *http.Response) error {
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return fmt.Errorf("the items provider answered with status %d", res.StatusCode)
+	}
+	return nil
+}
+
+//get calls the provider forwarding the request ID, so calls can be correlated on both sides
+func (e *externalService) get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if id := requestid.FromContext(ctx); id != "" {
+		req.Header.Set(requestid.HeaderKey, id)
+	}
+	return e.client.Do(req)
+}
@@ -2,13 +2,16 @@ package item_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
-	"io/ioutil"
+	"io"
 	"net/http"
 	"testing"
 
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
 
 	"github.com/sirupsen/logrus"
@@ -31,7 +34,7 @@ func TestHealthOK(t *testing.T) {
 		},
 	)
 
-	err := svc.Health()
+	err := svc.Health(context.TODO())
 	if err != nil {
 		t.Fatalf("Error was not expected")
 	}
@@ -54,7 +57,7 @@ func TestHealthStatusIncorrect(t *testing.T) {
 		},
 	)
 
-	err := svc.Health()
+	err := svc.Health(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -69,7 +72,7 @@ func TestHealthError(t *testing.T) {
 		},
 	)
 
-	err := svc.Health()
+	err := svc.Health(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -84,7 +87,7 @@ func TestHealthDecodingError(t *testing.T) {
 		},
 	)
 
-	err := svc.Health()
+	err := svc.Health(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -109,7 +112,7 @@ func TestGetItem(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetItem("someItemID")
+	_, err := svc.GetItem(context.TODO(), "someItemID")
 	if err != nil {
 		t.Fatalf("Error was not expected")
 	}
@@ -125,7 +128,7 @@ func TestGetItemNotFound(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetItem("someItemID")
+	_, err := svc.GetItem(context.TODO(), "someItemID")
 	if err == nil {
 		t.Fatalf("Error was not expected")
 	}
@@ -140,7 +143,7 @@ func TestGetItemApiFailure(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetItem("someItemID")
+	_, err := svc.GetItem(context.TODO(), "someItemID")
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -155,7 +158,7 @@ func TestGetItemWrongResponse(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetItem("someItemID")
+	_, err := svc.GetItem(context.TODO(), "someItemID")
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -179,7 +182,7 @@ func TestGetItemFloatParseError(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetItem("someItemID")
+	_, err := svc.GetItem(context.TODO(), "someItemID")
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -206,7 +209,7 @@ func TestGetAllItems(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetAllItems()
+	_, err := svc.GetAllItems(context.TODO())
 	if err != nil {
 		t.Fatalf("Error was not expected")
 	}
@@ -221,7 +224,7 @@ func TestGetAllItemsApiFailure(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetAllItems()
+	_, err := svc.GetAllItems(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -236,7 +239,7 @@ func TestGetAllItemsWrongResponse(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetAllItems()
+	_, err := svc.GetAllItems(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
@@ -262,27 +265,96 @@ func TestGetAllItemsFloatParseError(t *testing.T) {
 		},
 	)
 
-	_, err := svc.GetAllItems()
+	_, err := svc.GetAllItems(context.TODO())
 	if err == nil {
 		t.Fatalf("Error was expected")
 	}
 }
 
+func TestRequestIDForwarded(t *testing.T) {
+	client := &itemClientMock{
+		response: viewmodels.ExternalGetItemResponse{
+			Data: viewmodels.ExternalItem{ID: "1", Name: "Some Item", Price: "12.34"},
+		},
+	}
+	svc := item.NewExternalService(logrus.New(), client)
+
+	ctx := requestid.NewContext(context.TODO(), "some-request-id")
+	if _, err := svc.GetItem(ctx, "1"); err != nil {
+		t.Fatalf("Error was not expected")
+	}
+	if client.lastRequest.Header.Get(requestid.HeaderKey) != "some-request-id" {
+		t.Fatalf("Request ID was not forwarded")
+	}
+}
+
+func TestProviderFailureStatus(t *testing.T) {
+	for _, status := range []int{http.StatusInternalServerError, http.StatusBadGateway, http.StatusTooManyRequests} {
+		client := &itemClientMock{
+			responseStatusCode: status,
+			response: viewmodels.ExternalHealthResponse{
+				Data: viewmodels.ExternalHealth{Status: "OK"},
+			},
+		}
+		svc := item.NewExternalService(logrus.New(), client)
+
+		if err := svc.Health(context.TODO()); err == nil {
+			t.Fatalf("Health was expected to fail on %d", status)
+		}
+		if _, err := svc.GetItem(context.TODO(), "1"); err == nil || err.Error() == errors.ItemNotFoundOnProviderCode {
+			t.Fatalf("Unexpected GetItem error on %d: %v", status, err)
+		}
+		if _, err := svc.GetAllItems(context.TODO()); err == nil {
+			t.Fatalf("GetAllItems was expected to fail on %d", status)
+		}
+		if client.openBodies != 0 {
+			t.Fatalf("Response bodies were expected to be closed")
+		}
+	}
+}
+
+func TestResponseBodiesClosed(t *testing.T) {
+	client := &itemClientMock{}
+	svc := item.NewExternalService(logrus.New(), client)
+
+	svc.Health(context.TODO())
+	svc.GetItem(context.TODO(), "1")
+	svc.GetAllItems(context.TODO())
+	if client.openBodies != 0 {
+		t.Fatalf("%d response bodies were not closed", client.openBodies)
+	}
+}
+
 //*****ItemClientMock
 
 type itemClientMock struct {
 	response           interface{}
 	responseStatusCode int
 	shouldFail         bool
+	lastRequest        *http.Request
+	openBodies         int
+}
+
+//trackedBody counts the bodies of the mock that are not closed yet
+type trackedBody struct {
+	io.Reader
+	mock *itemClientMock
+}
+
+func (b trackedBody) Close() error {
+	b.mock.openBodies--
+	return nil
 }
 
-func (i *itemClientMock) Get(url string) (*http.Response, error) {
+func (i *itemClientMock) Do(req *http.Request) (*http.Response, error) {
+	i.lastRequest = req
 	if i.shouldFail {
 		return nil, fmt.Errorf("Mock asked to fail")
 	}
 	b, _ := json.Marshal(i.response)
-	resp := &http.Response{}
-	resp.Body = ioutil.NopCloser(bytes.NewReader(b))
+	resp := &http.Response{StatusCode: http.StatusOK}
+	resp.Body = trackedBody{Reader: bytes.NewReader(b), mock: i}
+	i.openBodies++
 	if i.responseStatusCode != 0 {
 		resp.StatusCode = i.responseStatusCode
 	}
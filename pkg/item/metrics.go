@@ -0,0 +1,70 @@
+package item
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+type instrumentedService struct {
+	next     ExternalService
+	calls    *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+}
+
+//NewInstrumentedService wraps an ExternalService recording count and duration of every provider call,
+//labelled by operation and outcome (ok, not_found or error)
+func NewInstrumentedService(next ExternalService, reg prometheus.Registerer) ExternalService {
+	s := &instrumentedService{
+		next: next,
+		calls: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "provider_requests_total",
+			Help: "Calls to the products provider by operation and outcome.",
+		}, []string{"operation", "outcome"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "provider_request_duration_seconds",
+			Help:    "Duration of calls to the products provider by operation and outcome.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "outcome"}),
+	}
+	reg.MustRegister(s.calls, s.duration)
+	return s
+}
+
+func (s *instrumentedService) Health(ctx context.Context) error {
+	start := time.Now()
+	err := s.next.Health(ctx)
+	s.observe("health", start, err)
+	return err
+}
+
+func (s *instrumentedService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	start := time.Now()
+	item, err := s.next.GetItem(ctx, id)
+	s.observe("get_item", start, err)
+	return item, err
+}
+
+func (s *instrumentedService) GetAllItems(ctx context.Context) ([]models.Item, error) {
+	start := time.Now()
+	items, err := s.next.GetAllItems(ctx)
+	s.observe("get_all_items", start, err)
+	return items, err
+}
+
+func (s *instrumentedService) observe(operation string, start time.Time, err error) {
+	outcome := "ok"
+	sErr := &serviceErrors.ServiceError{}
+	if errors.As(err, sErr) && sErr.Code == serviceErrors.ItemNotFoundOnProviderCode {
+		outcome = "not_found"
+	} else if err != nil {
+		outcome = "error"
+	}
+	s.calls.WithLabelValues(operation, outcome).Inc()
+	s.duration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
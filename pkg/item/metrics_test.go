@@ -0,0 +1,39 @@
+package item_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/sirupsen/logrus"
+)
+
+func TestInstrumentedServiceOutcomes(t *testing.T) {
+	client := &itemClientMock{
+		response: viewmodels.ExternalGetItemResponse{
+			Data: viewmodels.ExternalItem{ID: "1", Name: "Some Item", Price: "12.34"},
+		},
+	}
+	reg := prometheus.NewRegistry()
+	svc := item.NewInstrumentedService(item.NewExternalService(logrus.New(), client), reg)
+
+	svc.GetItem(context.TODO(), "1")
+
+	client.responseStatusCode = http.StatusNotFound
+	svc.GetItem(context.TODO(), "2")
+
+	client.shouldFail = true
+	svc.GetAllItems(context.TODO())
+
+	if testutil.CollectAndCount(reg, "provider_requests_total") != 3 {
+		t.Fatalf("Expected one series per operation and outcome")
+	}
+	if testutil.CollectAndCount(reg, "provider_request_duration_seconds") != 3 {
+		t.Fatalf("Expected one histogram per operation and outcome")
+	}
+}
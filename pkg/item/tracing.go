@@ -0,0 +1,53 @@
+package item
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tracing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type tracedClient struct {
+	next   ItemClient
+	tracer trace.Tracer
+}
+
+//NewTracedClient wraps an ItemClient creating a client span for every provider call
+//and propagating the trace context to the provider in the traceparent header
+func NewTracedClient(next ItemClient, tp trace.TracerProvider) ItemClient {
+	return &tracedClient{
+		next:   next,
+		tracer: tp.Tracer("github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"),
+	}
+}
+
+func (c *tracedClient) Do(req *http.Request) (*http.Response, error) {
+	ctx, span := c.tracer.Start(req.Context(), fmt.Sprintf("HTTP %s", req.Method),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.HTTPMethod(req.Method),
+			semconv.HTTPURL(req.URL.String()),
+			semconv.NetPeerName(req.URL.Hostname()),
+		),
+	)
+	req = req.Clone(ctx)
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	res, err := c.next.Do(req)
+	if err != nil {
+		tracing.End(span, err)
+		return res, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCode(res.StatusCode))
+	if res.StatusCode >= http.StatusInternalServerError {
+		span.SetStatus(codes.Error, res.Status)
+	}
+	span.End()
+	return res, nil
+}
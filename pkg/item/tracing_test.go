@@ -0,0 +1,67 @@
+package item_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestTracedClientPropagatesTraceparent(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	client := &itemClientMock{
+		response: viewmodels.ExternalGetItemResponse{
+			Data: viewmodels.ExternalItem{ID: "1", Name: "Some Item", Price: "12.34"},
+		},
+	}
+	svc := item.NewExternalService(logrus.New(), item.NewTracedClient(client, tp))
+
+	ctx, parent := tp.Tracer("test").Start(context.TODO(), "parent")
+	svc.GetItem(ctx, "1")
+	parent.End()
+
+	spans := sr.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("Expected a client span and its parent, got %d", len(spans))
+	}
+	clientSpan := spans[0]
+	if clientSpan.SpanKind() != trace.SpanKindClient {
+		t.Fatalf("Expected a client span")
+	}
+	if clientSpan.Parent().SpanID() != parent.SpanContext().SpanID() {
+		t.Fatalf("Client span is not a child of the caller span")
+	}
+
+	traceparent := client.lastRequest.Header.Get("traceparent")
+	expected := "00-" + clientSpan.SpanContext().TraceID().String() + "-" + clientSpan.SpanContext().SpanID().String() + "-01"
+	if traceparent != expected {
+		t.Fatalf("Unexpected traceparent %q, expected %q", traceparent, expected)
+	}
+}
+
+func TestTracedClientError(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+
+	svc := item.NewExternalService(logrus.New(), item.NewTracedClient(&itemClientMock{shouldFail: true}, tp))
+	svc.GetAllItems(context.TODO())
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	if len(spans[0].Events()) != 1 || spans[0].Status().Code.String() != "Error" {
+		t.Fatalf("Expected the error to be recorded on the span")
+	}
+}
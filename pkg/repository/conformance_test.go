@@ -0,0 +1,113 @@
+package repository_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+//testConformance is the behaviour every CartRepository backend must have
+func testConformance(t *testing.T, newRepository func(t *testing.T) repository.CartRepository) {
+	ctx := context.TODO()
+
+	t.Run("GetMissing", func(t *testing.T) {
+		r := newRepository(t)
+		if _, err := r.Get(ctx, "missing"); !errors.Is(err, repository.ErrCartNotFound) {
+			t.Fatalf("Expected ErrCartNotFound, got %v", err)
+		}
+	})
+
+	t.Run("SaveAndGet", func(t *testing.T) {
+		r := newRepository(t)
+		cart := models.Cart{
+			ID: "someCart",
+			Items: []models.Item{
+				{ID: "2", Quantity: 3, Name: "Not Stored", Price: 1.5},
+				{ID: "1", Quantity: 1},
+			},
+		}
+		if err := r.Save(ctx, cart); err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+
+		got, err := r.Get(ctx, "someCart")
+		if err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		if got.ID != "someCart" || len(got.Items) != 2 {
+			t.Fatalf("Unexpected cart %+v", got)
+		}
+		expected := []models.Item{{ID: "2", Quantity: 3}, {ID: "1", Quantity: 1}}
+		for idx, item := range expected {
+			if got.Items[idx] != item {
+				t.Fatalf("Items were expected in order with ID and quantity only, got %+v", got.Items)
+			}
+		}
+	})
+
+	t.Run("SaveEmpty", func(t *testing.T) {
+		r := newRepository(t)
+		if err := r.Save(ctx, models.Cart{ID: "emptyCart"}); err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		got, err := r.Get(ctx, "emptyCart")
+		if err != nil || got.ID != "emptyCart" || len(got.Items) != 0 {
+			t.Fatalf("Unexpected cart %+v, %v", got, err)
+		}
+	})
+
+	t.Run("SaveReplaces", func(t *testing.T) {
+		r := newRepository(t)
+		r.Save(ctx, models.Cart{ID: "someCart", Items: []models.Item{{ID: "1", Quantity: 1}, {ID: "2", Quantity: 1}}})
+		if err := r.Save(ctx, models.Cart{ID: "someCart", Items: []models.Item{{ID: "2", Quantity: 5}}}); err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		got, _ := r.Get(ctx, "someCart")
+		if len(got.Items) != 1 || got.Items[0].ID != "2" || got.Items[0].Quantity != 5 {
+			t.Fatalf("Cart was expected to be replaced, got %+v", got)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		r := newRepository(t)
+		r.Save(ctx, models.Cart{ID: "someCart", Items: []models.Item{{ID: "1", Quantity: 1}}})
+		if err := r.Delete(ctx, "someCart"); err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		if _, err := r.Get(ctx, "someCart"); !errors.Is(err, repository.ErrCartNotFound) {
+			t.Fatalf("Cart was expected to be deleted, got %v", err)
+		}
+		if err := r.Delete(ctx, "someCart"); !errors.Is(err, repository.ErrCartNotFound) {
+			t.Fatalf("Expected ErrCartNotFound, got %v", err)
+		}
+	})
+
+	t.Run("TenantIsolation", func(t *testing.T) {
+		r := newRepository(t)
+		shopA := tenant.NewContext(ctx, "shop-a")
+		shopB := tenant.NewContext(ctx, "shop-b")
+		r.Save(shopA, models.Cart{ID: "someCart", Items: []models.Item{{ID: "1", Quantity: 1}}})
+
+		if _, err := r.Get(shopB, "someCart"); !errors.Is(err, repository.ErrCartNotFound) {
+			t.Fatalf("Cart of another tenant was reachable, got %v", err)
+		}
+		if err := r.Delete(shopB, "someCart"); !errors.Is(err, repository.ErrCartNotFound) {
+			t.Fatalf("Cart of another tenant was deletable, got %v", err)
+		}
+		r.Save(shopB, models.Cart{ID: "someCart"})
+		cart, err := r.Get(shopA, "someCart")
+		if err != nil || len(cart.Items) != 1 {
+			t.Fatalf("Cart was expected untouched by another tenant, got %+v, %v", cart, err)
+		}
+	})
+
+	t.Run("Alive", func(t *testing.T) {
+		if !newRepository(t).Alive(ctx) {
+			t.Fatalf("Repository was expected to be alive")
+		}
+	})
+}
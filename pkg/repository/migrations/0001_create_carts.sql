@@ -0,0 +1,13 @@
+CREATE TABLE carts (
+    id         TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE cart_items (
+    cart_id  TEXT    NOT NULL REFERENCES carts (id) ON DELETE CASCADE,
+    item_id  TEXT    NOT NULL,
+    quantity INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (cart_id, item_id)
+);
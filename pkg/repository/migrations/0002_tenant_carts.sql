@@ -0,0 +1,27 @@
+-- Carts belong to a tenant, carts created before tenants existed belong to the default one.
+-- SQLite can't change a primary key, so the tables are rebuilt.
+CREATE TABLE tenant_carts (
+    tenant     TEXT NOT NULL DEFAULT 'default',
+    id         TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (tenant, id)
+);
+
+CREATE TABLE tenant_cart_items (
+    tenant   TEXT    NOT NULL DEFAULT 'default',
+    cart_id  TEXT    NOT NULL,
+    item_id  TEXT    NOT NULL,
+    quantity INTEGER NOT NULL,
+    position INTEGER NOT NULL,
+    PRIMARY KEY (tenant, cart_id, item_id),
+    FOREIGN KEY (tenant, cart_id) REFERENCES tenant_carts (tenant, id) ON DELETE CASCADE
+);
+
+INSERT INTO tenant_carts (id, created_at, updated_at) SELECT id, created_at, updated_at FROM carts;
+INSERT INTO tenant_cart_items (cart_id, item_id, quantity, position) SELECT cart_id, item_id, quantity, position FROM cart_items;
+
+DROP TABLE cart_items;
+DROP TABLE carts;
+ALTER TABLE tenant_carts RENAME TO carts;
+ALTER TABLE tenant_cart_items RENAME TO cart_items;
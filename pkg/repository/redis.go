@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+)
+
+type redisRepository struct {
+	cache cache.Cache
+}
+
+//NewRedisRepository gives a CartRepository keeping every cart as a value of the Redis cache, keyed by cart ID.
+//Values are encoded with the codec the cache is configured with (CACHE_CODEC), JSON by default.
+//Carts are lost if Redis is flushed.
+func NewRedisRepository(c cache.Cache) CartRepository {
+	return &redisRepository{
+		cache: c,
+	}
+}
+
+func (r *redisRepository) Save(ctx context.Context, cart models.Cart) error {
+	stored := models.Cart{ID: cart.ID, Items: make([]models.Item, len(cart.Items))}
+	for idx, item := range cart.Items {
+		stored.Items[idx] = models.Item{ID: item.ID, Quantity: item.Quantity}
+	}
+	return r.cache.Set(ctx, cart.ID, stored)
+}
+
+func (r *redisRepository) Get(ctx context.Context, cartID string) (models.Cart, error) {
+	cart := models.Cart{}
+	err := r.cache.Get(ctx, cartID, &cart)
+	if errors.Is(err, cache.ErrNotFound) {
+		return models.Cart{}, ErrCartNotFound
+	}
+	if err != nil {
+		return models.Cart{}, err
+	}
+	return cart, nil
+}
+
+func (r *redisRepository) Delete(ctx context.Context, cartID string) error {
+	err := r.cache.Del(ctx, cartID)
+	if errors.Is(err, cache.ErrNotFound) {
+		return ErrCartNotFound
+	}
+	return err
+}
+
+func (r *redisRepository) Alive(ctx context.Context) bool {
+	return r.cache.Alive(ctx)
+}
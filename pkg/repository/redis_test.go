@@ -0,0 +1,19 @@
+package repository_test
+
+import (
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedisRepositoryConformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) repository.CartRepository {
+		client := redis.NewClient(&redis.Options{Addr: miniredis.RunT(t).Addr()})
+		return repository.NewRedisRepository(cache.NewRedisCache(logrus.New(), 0, client))
+	})
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+)
+
+//Backends a CartRepository can be stored on
+const (
+	BackendRedis  = "redis"
+	BackendSQLite = "sqlite"
+)
+
+//ErrCartNotFound is returned when the cart does not exist in the repository
+var ErrCartNotFound = errors.New("cart not found")
+
+//CartRepository stores carts. Only the ID and quantity of the items are stored,
+//names and prices come from the products provider.
+type CartRepository interface {
+	//Save creates or replaces the cart
+	Save(ctx context.Context, cart models.Cart) error
+	Get(ctx context.Context, cartID string) (models.Cart, error)
+	Delete(ctx context.Context, cartID string) error
+	Alive(ctx context.Context) bool
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+
+	//registers the sqlite3 driver
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+type sqliteRepository struct {
+	db     *sql.DB
+	logger *logrus.Logger
+}
+
+//OpenSQLite opens, creating it if needed, the SQLite database at path and gives a CartRepository on it
+func OpenSQLite(ctx context.Context, logger *logrus.Logger, path string) (CartRepository, error) {
+	//foreign keys are off by default in SQLite, items are removed with their cart through them
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_foreign_keys=on&_busy_timeout=5000", path))
+	if err != nil {
+		return nil, err
+	}
+	return NewSQLiteRepository(ctx, logger, db)
+}
+
+//NewSQLiteRepository gives a CartRepository on a SQLite database, running the pending migrations first
+func NewSQLiteRepository(ctx context.Context, logger *logrus.Logger, db *sql.DB) (CartRepository, error) {
+	r := &sqliteRepository{
+		db:     db,
+		logger: logger,
+	}
+	if err := r.migrate(ctx); err != nil {
+		logger.WithContext(ctx).WithError(err).Error("repository_error")
+		return nil, err
+	}
+	return r, nil
+}
+
+//migrate applies, in order and once, every file in migrations/. Files are named <version>_<description>.sql.
+func (r *sqliteRepository) migrate(ctx context.Context) error {
+	_, err := r.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+	)`)
+	if err != nil {
+		return err
+	}
+
+	current := 0
+	err = r.db.QueryRowContext(ctx, "SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Scan(&current)
+	if err != nil {
+		return err
+	}
+
+	files, err := migrations.ReadDir("migrations")
+	if err != nil {
+		return err
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Name() < files[j].Name() })
+
+	for _, file := range files {
+		version, err := strconv.Atoi(strings.SplitN(file.Name(), "_", 2)[0])
+		if err != nil {
+			return fmt.Errorf("migration %s: %w", file.Name(), err)
+		}
+		if version <= current {
+			continue
+		}
+		script, err := migrations.ReadFile("migrations/" + file.Name())
+		if err != nil {
+			return err
+		}
+		if err := r.inTx(ctx, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+				return fmt.Errorf("migration %s: %w", file.Name(), err)
+			}
+			_, err := tx.ExecContext(ctx, "INSERT INTO schema_migrations (version) VALUES (?)", version)
+			return err
+		}); err != nil {
+			return err
+		}
+		r.logger.WithContext(ctx).WithField("migration", file.Name()).Info("Migration Applied")
+	}
+	return nil
+}
+
+func (r *sqliteRepository) Save(ctx context.Context, cart models.Cart) error {
+	t := tenant.FromContext(ctx)
+	err := r.inTx(ctx, func(tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO carts (tenant, id) VALUES (?, ?)
+			ON CONFLICT (tenant, id) DO UPDATE SET updated_at = CURRENT_TIMESTAMP`, t, cart.ID)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM cart_items WHERE tenant = ? AND cart_id = ?", t, cart.ID); err != nil {
+			return err
+		}
+		for position, item := range cart.Items {
+			_, err := tx.ExecContext(ctx, "INSERT INTO cart_items (tenant, cart_id, item_id, quantity, position) VALUES (?, ?, ?, ?, ?)",
+				t, cart.ID, item.ID, item.Quantity, position)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+	}
+	return err
+}
+
+func (r *sqliteRepository) Get(ctx context.Context, cartID string) (models.Cart, error) {
+	t := tenant.FromContext(ctx)
+	cart := models.Cart{}
+	err := r.db.QueryRowContext(ctx, "SELECT id FROM carts WHERE tenant = ? AND id = ?", t, cartID).Scan(&cart.ID)
+	if err == sql.ErrNoRows {
+		return models.Cart{}, ErrCartNotFound
+	}
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+		return models.Cart{}, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, "SELECT item_id, quantity FROM cart_items WHERE tenant = ? AND cart_id = ? ORDER BY position", t, cartID)
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+		return models.Cart{}, err
+	}
+	defer rows.Close()
+
+	cart.Items = []models.Item{}
+	for rows.Next() {
+		item := models.Item{}
+		if err := rows.Scan(&item.ID, &item.Quantity); err != nil {
+			r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+			return models.Cart{}, err
+		}
+		cart.Items = append(cart.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+		return models.Cart{}, err
+	}
+	return cart, nil
+}
+
+func (r *sqliteRepository) Delete(ctx context.Context, cartID string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM carts WHERE tenant = ? AND id = ?", tenant.FromContext(ctx), cartID)
+	if err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository_error")
+		return err
+	}
+	if affected, err := res.RowsAffected(); err == nil && affected == 0 {
+		return ErrCartNotFound
+	}
+	return nil
+}
+
+func (r *sqliteRepository) Alive(ctx context.Context) bool {
+	if err := r.db.PingContext(ctx); err != nil {
+		r.logger.WithContext(ctx).WithError(err).Error("repository not connected")
+		return false
+	}
+	return true
+}
+
+func (r *sqliteRepository) inTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
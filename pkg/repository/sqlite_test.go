@@ -0,0 +1,70 @@
+package repository_test
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
+
+	"github.com/sirupsen/logrus"
+)
+
+func TestSQLiteRepositoryConformance(t *testing.T) {
+	testConformance(t, func(t *testing.T) repository.CartRepository {
+		r, err := repository.OpenSQLite(context.TODO(), logrus.New(), filepath.Join(t.TempDir(), "carts.db"))
+		if err != nil {
+			t.Fatalf("Error was not expected: %v", err)
+		}
+		return r
+	})
+}
+
+func TestSQLiteRepositoryReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "carts.db")
+	r, err := repository.OpenSQLite(context.TODO(), logrus.New(), path)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	r.Save(context.TODO(), models.Cart{ID: "someCart", Items: []models.Item{{ID: "1", Quantity: 2}}})
+
+	//migrations already applied must be skipped and carts kept
+	r, err = repository.OpenSQLite(context.TODO(), logrus.New(), path)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	cart, err := r.Get(context.TODO(), "someCart")
+	if err != nil || len(cart.Items) != 1 || cart.Items[0].Quantity != 2 {
+		t.Fatalf("Cart was expected to survive a restart, got %+v, %v", cart, err)
+	}
+}
+
+func TestSQLiteRepositoryTenantMigration(t *testing.T) {
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "carts.db"))
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	//a database from before tenants existed
+	_, err = db.Exec(`
+		CREATE TABLE schema_migrations (version INTEGER PRIMARY KEY, applied_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		INSERT INTO schema_migrations (version) VALUES (1);
+		CREATE TABLE carts (id TEXT PRIMARY KEY, created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP, updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP);
+		CREATE TABLE cart_items (cart_id TEXT NOT NULL REFERENCES carts (id) ON DELETE CASCADE, item_id TEXT NOT NULL, quantity INTEGER NOT NULL, position INTEGER NOT NULL, PRIMARY KEY (cart_id, item_id));
+		INSERT INTO carts (id) VALUES ('oldCart');
+		INSERT INTO cart_items (cart_id, item_id, quantity, position) VALUES ('oldCart', '1', 4, 0);
+	`)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+
+	r, err := repository.NewSQLiteRepository(context.TODO(), logrus.New(), db)
+	if err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	cart, err := r.Get(context.TODO(), "oldCart")
+	if err != nil || len(cart.Items) != 1 || cart.Items[0].Quantity != 4 {
+		t.Fatalf("Existing carts were expected in the default tenant, got %+v, %v", cart, err)
+	}
+}
@@ -0,0 +1,57 @@
+package requestid
+
+import (
+	"context"
+	"regexp"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderKey = "X-Request-ID"
+	LogField  = "request_id"
+)
+
+type contextKey struct{}
+
+//validID keeps whatever a client sends us from breaking logs or headers
+var validID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+//New generates a fresh request ID
+func New() string {
+	return uuid.New().String()
+}
+
+//Valid tells whether an inbound ID is safe to propagate
+func Valid(id string) bool {
+	return validID.MatchString(id)
+}
+
+//NewContext stores the request ID in the context
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+//FromContext returns the request ID of the context, empty when there's none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+//Hook adds the request ID to every logrus entry logged with a context carrying one
+type Hook struct{}
+
+func (h Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h Hook) Fire(entry *logrus.Entry) error {
+	if id := FromContext(entry.Context); id != "" {
+		entry.Data[LogField] = id
+	}
+	return nil
+}
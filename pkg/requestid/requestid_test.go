@@ -0,0 +1,56 @@
+package requestid_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/sirupsen/logrus"
+)
+
+func TestContextRoundtrip(t *testing.T) {
+	ctx := requestid.NewContext(context.TODO(), "some-id")
+	if requestid.FromContext(ctx) != "some-id" {
+		t.Fatalf("Unexpected request ID")
+	}
+	if requestid.FromContext(context.TODO()) != "" {
+		t.Fatalf("No request ID was expected")
+	}
+}
+
+func TestValid(t *testing.T) {
+	if !requestid.Valid(requestid.New()) {
+		t.Fatalf("Generated IDs must be valid")
+	}
+	for _, id := range []string{"", "with spaces", "new\nline", strings.Repeat("a", 129)} {
+		if requestid.Valid(id) {
+			t.Fatalf("%q was not expected to be valid", id)
+		}
+	}
+}
+
+func TestHook(t *testing.T) {
+	buf := &bytes.Buffer{}
+	logger := logrus.New()
+	logger.SetOutput(buf)
+	logger.SetFormatter(&logrus.JSONFormatter{})
+	logger.AddHook(requestid.Hook{})
+
+	logger.WithContext(requestid.NewContext(context.TODO(), "some-id")).Info("with id")
+	entry := map[string]interface{}{}
+	json.Unmarshal(buf.Bytes(), &entry)
+	if entry[requestid.LogField] != "some-id" {
+		t.Fatalf("Request ID was expected in the entry: %s", buf.String())
+	}
+
+	buf.Reset()
+	logger.Info("without id")
+	entry = map[string]interface{}{}
+	json.Unmarshal(buf.Bytes(), &entry)
+	if _, ok := entry[requestid.LogField]; ok {
+		t.Fatalf("Request ID was not expected in the entry: %s", buf.String())
+	}
+}
@@ -2,17 +2,21 @@ package service
 
 import (
 	"context"
+	stderrors "errors"
 
-	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/cache"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/item"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
 	"github.com/google/uuid"
 )
 
 type CartService interface {
 	CreateCart(ctx context.Context) (models.Cart, error)
 	GetCart(ctx context.Context, cartID string) (models.Cart, error)
+	//GetStoredCart gives the cart as stored, items only have their ID and quantity.
+	//Callers that look up the items themselves use it to skip the provider calls of GetCart.
+	GetStoredCart(ctx context.Context, cartID string) (models.Cart, error)
 	GetAvailableItems(ctx context.Context) ([]models.Item, error)
 	GetItem(ctx context.Context, id string) (models.Item, error)
 	AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error)
@@ -22,17 +26,23 @@ type CartService interface {
 	DeleteCart(ctx context.Context, cartID string) error
 }
 
+//Causes of the bad requests the service rejects, whatever transport they come from
+var (
+	errMissingItemID   = stderrors.New("the item ID is required")
+	errInvalidQuantity = stderrors.New("the quantity must be at least 1")
+)
+
 type service struct {
 	//dependencies of the service
 	version         string
-	cache           cache.Cache
+	carts           repository.CartRepository
 	externalService item.ExternalService
 }
 
-func NewCartService(version string, cache cache.Cache, externalService item.ExternalService) CartService {
+func NewCartService(version string, carts repository.CartRepository, externalService item.ExternalService) CartService {
 	return &service{
 		version:         version,
-		cache:           cache,
+		carts:           carts,
 		externalService: externalService,
 	}
 }
@@ -43,51 +53,61 @@ func (s *service) CreateCart(ctx context.Context) (models.Cart, error) {
 		ID: cartID,
 	}
 
-	if err := s.cache.Set(cartID, cart); err != nil {
-		return models.Cart{}, errors.ServiceError{
-			Code: errors.CacheErrorCode,
-		}
+	if err := s.carts.Save(ctx, cart); err != nil {
+		return models.Cart{}, errors.Wrap(errors.CacheErrorCode, err)
 	}
 
 	return cart, nil
 }
 
 func (s *service) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
-	cart := models.Cart{}
-	err := s.cache.Get(cartID, &cart)
+	cart, err := s.carts.Get(ctx, cartID)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.CartNotFoundCode}
+		return models.Cart{}, repositoryError(err)
 	}
 
-	err = s.fetchItemsForCart(&cart)
+	err = s.fetchItemsForCart(ctx, &cart)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.ExternalApiErrorCode}
+		return models.Cart{}, errors.Wrap(errors.ExternalApiErrorCode, err)
 	}
 
 	return cart, nil
 }
 
+func (s *service) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	cart, err := s.carts.Get(ctx, cartID)
+	if err != nil {
+		return models.Cart{}, repositoryError(err)
+	}
+	return cart, nil
+}
+
 func (s *service) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
-	items, err := s.externalService.GetAllItems()
+	items, err := s.externalService.GetAllItems(ctx)
 	if err != nil {
-		return []models.Item{}, err
+		return []models.Item{}, externalError(err)
 	}
 	return items, nil
 }
 
 func (s *service) GetItem(ctx context.Context, id string) (models.Item, error) {
-	item, err := s.externalService.GetItem(id)
+	item, err := s.externalService.GetItem(ctx, id)
 	if err != nil {
-		return models.Item{}, err
+		return models.Item{}, externalError(err)
 	}
 	return item, nil
 }
 
 func (s *service) AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error) {
-	cart := models.Cart{}
-	err := s.cache.Get(cartID, &cart)
+	if itemID == "" {
+		return models.Cart{}, errors.Wrap(errors.BadRequestCode, errMissingItemID)
+	}
+	if quantity < 1 {
+		return models.Cart{}, errors.Wrap(errors.BadRequestCode, errInvalidQuantity)
+	}
+	cart, err := s.carts.Get(ctx, cartID)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.CartNotFoundCode}
+		return models.Cart{}, repositoryError(err)
 	}
 
 	for _, item := range cart.Items {
@@ -101,33 +121,35 @@ func (s *service) AddItemToCart(ctx context.Context, cartID, itemID string, quan
 		Quantity: quantity,
 	})
 
-	if err := s.cache.Set(cartID, cart); err != nil {
-		return models.Cart{}, err
+	if err := s.carts.Save(ctx, cart); err != nil {
+		return models.Cart{}, errors.Wrap(errors.CacheErrorCode, err)
 	}
 
-	err = s.fetchItemsForCart(&cart)
+	err = s.fetchItemsForCart(ctx, &cart)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.ExternalApiErrorCode}
+		return models.Cart{}, errors.Wrap(errors.ExternalApiErrorCode, err)
 	}
 
 	return cart, nil
 }
 func (s *service) ModifyItemInCart(ctx context.Context, cartID, itemID string, newQuantity int) (models.Cart, error) {
-	cart := models.Cart{}
-	err := s.cache.Get(cartID, &cart)
+	if newQuantity < 1 {
+		return models.Cart{}, errors.Wrap(errors.BadRequestCode, errInvalidQuantity)
+	}
+	cart, err := s.carts.Get(ctx, cartID)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.CartNotFoundCode}
+		return models.Cart{}, repositoryError(err)
 	}
 
 	for idx, item := range cart.Items {
 		if item.ID == itemID {
 			cart.Items[idx].Quantity = newQuantity
-			if err := s.cache.Set(cartID, cart); err != nil {
-				return models.Cart{}, err
+			if err := s.carts.Save(ctx, cart); err != nil {
+				return models.Cart{}, errors.Wrap(errors.CacheErrorCode, err)
 			}
-			err = s.fetchItemsForCart(&cart)
+			err = s.fetchItemsForCart(ctx, &cart)
 			if err != nil {
-				return models.Cart{}, errors.ServiceError{Code: errors.ExternalApiErrorCode}
+				return models.Cart{}, errors.Wrap(errors.ExternalApiErrorCode, err)
 			}
 			return cart, nil
 		}
@@ -136,10 +158,9 @@ func (s *service) ModifyItemInCart(ctx context.Context, cartID, itemID string, n
 	return models.Cart{}, errors.ServiceError{Code: errors.ItemNotFoundCode}
 }
 func (s *service) DeleteItemInCart(ctx context.Context, cartID, itemID string) (models.Cart, error) {
-	cart := models.Cart{}
-	err := s.cache.Get(cartID, &cart)
+	cart, err := s.carts.Get(ctx, cartID)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.CartNotFoundCode}
+		return models.Cart{}, repositoryError(err)
 	}
 
 	for idx, item := range cart.Items {
@@ -148,13 +169,13 @@ func (s *service) DeleteItemInCart(ctx context.Context, cartID, itemID string) (
 
 			cart.Items = append(cart.Items[:idx], cart.Items[idx+1:]...)
 
-			if err := s.cache.Set(cartID, cart); err != nil {
-				return models.Cart{}, err
+			if err := s.carts.Save(ctx, cart); err != nil {
+				return models.Cart{}, errors.Wrap(errors.CacheErrorCode, err)
 			}
 
-			err = s.fetchItemsForCart(&cart)
+			err = s.fetchItemsForCart(ctx, &cart)
 			if err != nil {
-				return models.Cart{}, errors.ServiceError{Code: errors.ExternalApiErrorCode}
+				return models.Cart{}, errors.Wrap(errors.ExternalApiErrorCode, err)
 			}
 
 			return cart, nil
@@ -164,30 +185,29 @@ func (s *service) DeleteItemInCart(ctx context.Context, cartID, itemID string) (
 	return models.Cart{}, errors.ServiceError{Code: errors.ItemNotFoundCode}
 }
 func (s *service) DeleteAllItemsInCart(ctx context.Context, cartID string) (models.Cart, error) {
-	cart := models.Cart{}
-	err := s.cache.Get(cartID, &cart)
+	cart, err := s.carts.Get(ctx, cartID)
 	if err != nil {
-		return models.Cart{}, errors.ServiceError{Code: errors.CartNotFoundCode}
+		return models.Cart{}, repositoryError(err)
 	}
 
 	cart.Items = []models.Item{}
-	if err := s.cache.Set(cartID, cart); err != nil {
-		return models.Cart{}, err
+	if err := s.carts.Save(ctx, cart); err != nil {
+		return models.Cart{}, errors.Wrap(errors.CacheErrorCode, err)
 	}
 
 	return cart, nil
 }
 func (s *service) DeleteCart(ctx context.Context, cartID string) error {
-	err := s.cache.Del(cartID)
+	err := s.carts.Delete(ctx, cartID)
 	if err != nil {
-		return errors.ServiceError{Code: errors.CartNotFoundCode}
+		return repositoryError(err)
 	}
 	return nil
 }
-func (s *service) fetchItemsForCart(cart *models.Cart) error {
+func (s *service) fetchItemsForCart(ctx context.Context, cart *models.Cart) error {
 	//We fetch information from the external service to fill in Name and Price
 	for idx, item := range cart.Items {
-		extItem, err := s.externalService.GetItem(item.ID)
+		extItem, err := s.externalService.GetItem(ctx, item.ID)
 		if err != nil {
 			return err
 		}
@@ -196,3 +216,20 @@ func (s *service) fetchItemsForCart(cart *models.Cart) error {
 	}
 	return nil
 }
+
+//repositoryError tells a missing cart from the repository failing, which may go away when retried
+func repositoryError(err error) error {
+	if stderrors.Is(err, repository.ErrCartNotFound) {
+		return errors.Wrap(errors.CartNotFoundCode, err)
+	}
+	return errors.Wrap(errors.CacheErrorCode, err)
+}
+
+//externalError keeps the service errors of the external service, the others are the provider failing
+func externalError(err error) error {
+	sErr := &errors.ServiceError{}
+	if stderrors.As(err, sErr) {
+		return err
+	}
+	return errors.Wrap(errors.ExternalApiErrorCode, err)
+}
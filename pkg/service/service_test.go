@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/repository"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
 )
 
 func TestCreateCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -25,7 +27,7 @@ func TestCreateCartOK(t *testing.T) {
 
 func TestCreateCartCacheFail(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldSetFail: true,
 		},
 		&externalMock{
@@ -41,7 +43,7 @@ func TestCreateCartCacheFail(t *testing.T) {
 
 func TestGetCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -55,7 +57,7 @@ func TestGetCartOK(t *testing.T) {
 
 func TestGetCartCacheFail(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldGetFail: true,
 		},
 		&externalMock{
@@ -69,9 +71,44 @@ func TestGetCartCacheFail(t *testing.T) {
 	}
 }
 
+func TestGetStoredCartSkipsProvider(t *testing.T) {
+	svc := service.NewCartService("unit-testing",
+		&repositoryMock{},
+		&externalMock{
+			shouldFail: true,
+		})
+
+	_, err := svc.GetStoredCart(context.TODO(), "testCartID")
+
+	if err != nil {
+		t.Fatalf("Service not Expected to call the external service")
+	}
+}
+
+func TestRepositoryErrors(t *testing.T) {
+	for expected, repo := range map[string]*repositoryMock{
+		errors.CartNotFoundCode: {missing: true},
+		errors.CacheErrorCode:   {shouldGetFail: true, shouldDelFail: true},
+	} {
+		svc := service.NewCartService("unit-testing", repo, &externalMock{})
+		if _, err := svc.GetCart(context.TODO(), "someCart"); err == nil || err.Error() != expected {
+			t.Fatalf("Expected %s, got %v", expected, err)
+		}
+		if _, err := svc.AddItemToCart(context.TODO(), "someCart", "someItem", 1); err == nil || err.Error() != expected {
+			t.Fatalf("Expected %s, got %v", expected, err)
+		}
+		if _, err := svc.GetStoredCart(context.TODO(), "someCart"); err == nil || err.Error() != expected {
+			t.Fatalf("Expected %s, got %v", expected, err)
+		}
+		if err := svc.DeleteCart(context.TODO(), "someCart"); err == nil || err.Error() != expected {
+			t.Fatalf("Expected %s, got %v", expected, err)
+		}
+	}
+}
+
 func TestGetCartExternalFail(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -85,7 +122,7 @@ func TestGetCartExternalFail(t *testing.T) {
 
 func TestGetAvailableItemsOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -99,7 +136,7 @@ func TestGetAvailableItemsOK(t *testing.T) {
 
 func TestGetAvailableItemsExternalFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -113,7 +150,7 @@ func TestGetAvailableItemsExternalFailure(t *testing.T) {
 
 func TestGetItemOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -127,7 +164,7 @@ func TestGetItemOK(t *testing.T) {
 
 func TestGetItemExternalFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -141,7 +178,7 @@ func TestGetItemExternalFailure(t *testing.T) {
 
 func TestAddItemToCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -155,7 +192,7 @@ func TestAddItemToCartOK(t *testing.T) {
 
 func TestAddItemToCartFailItemAlreadyAdded(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -167,9 +204,20 @@ func TestAddItemToCartFailItemAlreadyAdded(t *testing.T) {
 	}
 }
 
+func TestAddItemToCartBadRequest(t *testing.T) {
+	svc := service.NewCartService("unit-testing", &repositoryMock{}, &externalMock{})
+
+	for itemID, quantity := range map[string]int{"someItem": 0, "otherItem": -1, "": 1} {
+		_, err := svc.AddItemToCart(context.TODO(), "someCart", itemID, quantity)
+		if err == nil || err.Error() != errors.BadRequestCode {
+			t.Fatalf("Bad request was expected for %q %d, got %v", itemID, quantity, err)
+		}
+	}
+}
+
 func TestAddItemToCartCacheFailureGet(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldGetFail: true,
 		},
 		&externalMock{
@@ -185,7 +233,7 @@ func TestAddItemToCartCacheFailureGet(t *testing.T) {
 
 func TestAddItemToCartCacheFailureSet(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldSetFail: true,
 		},
 		&externalMock{
@@ -201,7 +249,7 @@ func TestAddItemToCartCacheFailureSet(t *testing.T) {
 
 func TestAddItemToCartExternalFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -215,7 +263,7 @@ func TestAddItemToCartExternalFailure(t *testing.T) {
 
 func TestModifyItemInCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -227,9 +275,18 @@ func TestModifyItemInCartOK(t *testing.T) {
 	}
 }
 
+func TestModifyItemInCartBadRequest(t *testing.T) {
+	svc := service.NewCartService("unit-testing", &repositoryMock{}, &externalMock{})
+
+	_, err := svc.ModifyItemInCart(context.TODO(), "someCart", "1-simple-Item", 0)
+	if err == nil || err.Error() != errors.BadRequestCode {
+		t.Fatalf("Bad request was expected, got %v", err)
+	}
+}
+
 func TestModifyItemInCartItemNotFound(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -243,7 +300,7 @@ func TestModifyItemInCartItemNotFound(t *testing.T) {
 
 func TestModifyItemInCartCacheGetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldGetFail: true,
 		},
 		&externalMock{
@@ -259,7 +316,7 @@ func TestModifyItemInCartCacheGetFailure(t *testing.T) {
 
 func TestModifyItemInCartCacheSetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldSetFail: true,
 		},
 		&externalMock{
@@ -275,7 +332,7 @@ func TestModifyItemInCartCacheSetFailure(t *testing.T) {
 
 func TestModifyItemInCartExternalFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -289,7 +346,7 @@ func TestModifyItemInCartExternalFailure(t *testing.T) {
 
 func TestDeleteItemInCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -303,7 +360,7 @@ func TestDeleteItemInCartOK(t *testing.T) {
 
 func TestDeleteItemInCartItemNotFound(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -317,7 +374,7 @@ func TestDeleteItemInCartItemNotFound(t *testing.T) {
 
 func TestDeleteItemInCartCacheGetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldGetFail: true,
 		},
 		&externalMock{
@@ -333,7 +390,7 @@ func TestDeleteItemInCartCacheGetFailure(t *testing.T) {
 
 func TestDeleteItemInCartCacheSetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldSetFail: true,
 		},
 		&externalMock{
@@ -349,7 +406,7 @@ func TestDeleteItemInCartCacheSetFailure(t *testing.T) {
 
 func TestDeleteItemInCartExternalFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: true,
 		})
@@ -363,7 +420,7 @@ func TestDeleteItemInCartExternalFailure(t *testing.T) {
 
 func TestDeleteAllItemsInCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -377,7 +434,7 @@ func TestDeleteAllItemsInCartOK(t *testing.T) {
 
 func TestDeleteAllItemsInCartCacheGetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldGetFail: true,
 		},
 		&externalMock{
@@ -393,7 +450,7 @@ func TestDeleteAllItemsInCartCacheGetFailure(t *testing.T) {
 
 func TestDeleteAllItemsInCartCacheSetFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldSetFail: true,
 		},
 		&externalMock{
@@ -409,7 +466,7 @@ func TestDeleteAllItemsInCartCacheSetFailure(t *testing.T) {
 
 func TestDeleteCartOK(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{},
+		&repositoryMock{},
 		&externalMock{
 			shouldFail: false,
 		})
@@ -423,7 +480,7 @@ func TestDeleteCartOK(t *testing.T) {
 
 func TestDeleteCartCacheFailure(t *testing.T) {
 	svc := service.NewCartService("unit-testing",
-		&cacheMock{
+		&repositoryMock{
 			shouldDelFail: true,
 		},
 		&externalMock{
@@ -439,45 +496,52 @@ func TestDeleteCartCacheFailure(t *testing.T) {
 
 //*************************Mocks********************
 
-//******** Cache Mock
+//******** Repository Mock
 
-type cacheMock struct {
+type repositoryMock struct {
 	shouldSetFail   bool
 	shouldGetFail   bool
 	shouldDelFail   bool
 	shouldAliveFail bool
+	missing         bool
 }
 
-func (c *cacheMock) Set(key string, value interface{}) error {
+func (c *repositoryMock) Save(ctx context.Context, cart models.Cart) error {
 	if c.shouldSetFail {
 		return fmt.Errorf("Mock was asked to fail")
 	}
 	return nil
 }
-func (c *cacheMock) Get(key string, here interface{}) error {
-	if c.shouldGetFail {
-		return fmt.Errorf("Mock was asked to fail")
+func (c *repositoryMock) Get(ctx context.Context, cartID string) (models.Cart, error) {
+	if c.missing {
+		return models.Cart{}, repository.ErrCartNotFound
 	}
-	m := here.(*models.Cart)
-
-	m.Items = []models.Item{
-		{
-			ID: "1-simple-Item",
-		},
-		{
-			ID: "2-simple-Item",
+	if c.shouldGetFail {
+		return models.Cart{}, fmt.Errorf("Mock was asked to fail")
+	}
+	return models.Cart{
+		ID: cartID,
+		Items: []models.Item{
+			{
+				ID: "1-simple-Item",
+			},
+			{
+				ID: "2-simple-Item",
+			},
 		},
-	}
-	return nil
+	}, nil
 }
-func (c *cacheMock) Del(key string) error {
+func (c *repositoryMock) Delete(ctx context.Context, cartID string) error {
+	if c.missing {
+		return repository.ErrCartNotFound
+	}
 	if c.shouldDelFail {
 		return fmt.Errorf("Mock was asked to fail")
 	}
 
 	return nil
 }
-func (c *cacheMock) Alive() bool {
+func (c *repositoryMock) Alive(ctx context.Context) bool {
 	return !c.shouldAliveFail
 }
 
@@ -486,20 +550,20 @@ type externalMock struct {
 	shouldFail bool
 }
 
-func (e *externalMock) Health() error {
+func (e *externalMock) Health(ctx context.Context) error {
 	if e.shouldFail {
 		return fmt.Errorf("External API Mock was asked to fail")
 	}
 	return nil
 }
 
-func (e *externalMock) GetItem(id string) (models.Item, error) {
+func (e *externalMock) GetItem(ctx context.Context, id string) (models.Item, error) {
 	if e.shouldFail {
 		return models.Item{}, fmt.Errorf("External Mock was asked to Fail")
 	}
 	return models.Item{}, nil
 }
-func (e *externalMock) GetAllItems() ([]models.Item, error) {
+func (e *externalMock) GetAllItems(ctx context.Context) ([]models.Item, error) {
 	if e.shouldFail {
 		return []models.Item{}, fmt.Errorf("External Mock was asked to Fail")
 	}
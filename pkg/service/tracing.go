@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	cartIDKey = attribute.Key("cart.id")
+	itemIDKey = attribute.Key("item.id")
+)
+
+type tracedService struct {
+	next   CartService
+	tracer trace.Tracer
+}
+
+//NewTracedCartService wraps a CartService creating a span for every method
+func NewTracedCartService(next CartService, tp trace.TracerProvider) CartService {
+	return &tracedService{
+		next:   next,
+		tracer: tp.Tracer("github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"),
+	}
+}
+
+func (s *tracedService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "CartService."+method, trace.WithAttributes(attrs...))
+}
+
+func (s *tracedService) CreateCart(ctx context.Context) (models.Cart, error) {
+	ctx, span := s.start(ctx, "CreateCart")
+	cart, err := s.next.CreateCart(ctx)
+	span.SetAttributes(cartIDKey.String(cart.ID))
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
+	ctx, span := s.start(ctx, "GetCart", cartIDKey.String(cartID))
+	cart, err := s.next.GetCart(ctx, cartID)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	ctx, span := s.start(ctx, "GetStoredCart", cartIDKey.String(cartID))
+	cart, err := s.next.GetStoredCart(ctx, cartID)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
+	ctx, span := s.start(ctx, "GetAvailableItems")
+	items, err := s.next.GetAvailableItems(ctx)
+	tracing.End(span, err)
+	return items, err
+}
+
+func (s *tracedService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	ctx, span := s.start(ctx, "GetItem", itemIDKey.String(id))
+	item, err := s.next.GetItem(ctx, id)
+	tracing.End(span, err)
+	return item, err
+}
+
+func (s *tracedService) AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error) {
+	ctx, span := s.start(ctx, "AddItemToCart", cartIDKey.String(cartID), itemIDKey.String(itemID))
+	cart, err := s.next.AddItemToCart(ctx, cartID, itemID, quantity)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) ModifyItemInCart(ctx context.Context, cartID, itemID string, newQuantity int) (models.Cart, error) {
+	ctx, span := s.start(ctx, "ModifyItemInCart", cartIDKey.String(cartID), itemIDKey.String(itemID))
+	cart, err := s.next.ModifyItemInCart(ctx, cartID, itemID, newQuantity)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) DeleteItemInCart(ctx context.Context, cartID, itemID string) (models.Cart, error) {
+	ctx, span := s.start(ctx, "DeleteItemInCart", cartIDKey.String(cartID), itemIDKey.String(itemID))
+	cart, err := s.next.DeleteItemInCart(ctx, cartID, itemID)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) DeleteAllItemsInCart(ctx context.Context, cartID string) (models.Cart, error) {
+	ctx, span := s.start(ctx, "DeleteAllItemsInCart", cartIDKey.String(cartID))
+	cart, err := s.next.DeleteAllItemsInCart(ctx, cartID)
+	tracing.End(span, err)
+	return cart, err
+}
+
+func (s *tracedService) DeleteCart(ctx context.Context, cartID string) error {
+	ctx, span := s.start(ctx, "DeleteCart", cartIDKey.String(cartID))
+	err := s.next.DeleteCart(ctx, cartID)
+	tracing.End(span, err)
+	return err
+}
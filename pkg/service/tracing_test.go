@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracedCartServiceSpans(t *testing.T) {
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	svc := service.NewTracedCartService(service.NewCartService("unit-testing",
+		&repositoryMock{
+			shouldGetFail: true,
+		},
+		&externalMock{}), tp)
+
+	svc.GetCart(context.TODO(), "someCart")
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one span, got %d", len(spans))
+	}
+	if spans[0].Name() != "CartService.GetCart" {
+		t.Fatalf("Unexpected span name %s", spans[0].Name())
+	}
+	if spans[0].Status().Code != codes.Error {
+		t.Fatalf("Expected the error to be recorded on the span")
+	}
+	found := false
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == "cart.id" && attr.Value.AsString() == "someCart" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("Expected cart.id attribute")
+	}
+}
@@ -0,0 +1,127 @@
+package tenant
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderKey = "X-Tenant-ID"
+	//MetadataKey carries the tenant on gRPC calls
+	MetadataKey = "x-tenant-id"
+	LogField    = "tenant"
+
+	//Default is the tenant of requests that don't name one
+	Default = "default"
+)
+
+//Where the tenant of a request is read from
+const (
+	SourceHeader = "header"
+	SourceHost   = "host"
+)
+
+type contextKey struct{}
+
+//validTenant keeps tenants usable as part of Redis keys and headers.
+//No ':' nor '_', so a tenant namespace never overlaps another namespace such as cart_presence.
+var validTenant = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,62}$`)
+
+//Valid tells whether a tenant name is acceptable
+func Valid(tenant string) bool {
+	return validTenant.MatchString(tenant)
+}
+
+//NewContext stores the tenant in the context
+func NewContext(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, contextKey{}, tenant)
+}
+
+//FromContext returns the tenant of the context, Default when there's none
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return Default
+	}
+	if tenant, ok := ctx.Value(contextKey{}).(string); ok && tenant != "" {
+		return tenant
+	}
+	return Default
+}
+
+//Resolver finds the tenant of a request
+type Resolver struct {
+	//Source is SourceHeader (X-Tenant-ID) or SourceHost (first label of the Host, shop-a.example.com is shop-a)
+	Source string
+	//Allowed restricts the accepted tenants, requests that don't name a tenant are Default, which must be allowed too.
+	//When empty, tenants named by the client in a header or metadata are rejected and only Default is served:
+	//any client could name any tenant otherwise. With SourceHost any valid tenant is accepted, hosts are routed to us.
+	Allowed []string
+}
+
+//Resolve gives the tenant of the request, false when it is invalid or not allowed.
+//Requests that don't name a tenant belong to Default.
+func (r Resolver) Resolve(req *http.Request) (string, bool) {
+	tenant := ""
+	switch r.Source {
+	case SourceHost:
+		host := req.Host
+		if h, _, err := net.SplitHostPort(host); err == nil {
+			host = h
+		}
+		//a bare host or an IP doesn't name a tenant
+		if labels := strings.Split(host, "."); len(labels) > 2 && net.ParseIP(host) == nil {
+			tenant = labels[0]
+		}
+	default:
+		tenant = req.Header.Get(HeaderKey)
+	}
+	return r.check(strings.ToLower(tenant))
+}
+
+//Check validates a tenant received by other means than HTTP, such as gRPC metadata
+func (r Resolver) Check(tenant string) (string, bool) {
+	return r.check(strings.ToLower(tenant))
+}
+
+func (r Resolver) check(tenant string) (string, bool) {
+	if tenant == "" {
+		tenant = Default
+	}
+	if !Valid(tenant) {
+		return "", false
+	}
+	if len(r.Allowed) == 0 {
+		if r.Source != SourceHost && tenant != Default {
+			return "", false
+		}
+		return tenant, true
+	}
+	for _, allowed := range r.Allowed {
+		if allowed == tenant {
+			return tenant, true
+		}
+	}
+	return "", false
+}
+
+//Hook adds the tenant to every logrus entry logged with a context carrying one
+type Hook struct{}
+
+func (h Hook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+func (h Hook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	if tenant, ok := entry.Context.Value(contextKey{}).(string); ok && tenant != "" {
+		entry.Data[LogField] = tenant
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+package tenant_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+)
+
+func TestContextRoundtrip(t *testing.T) {
+	ctx := tenant.NewContext(context.TODO(), "shop-a")
+	if tenant.FromContext(ctx) != "shop-a" {
+		t.Fatalf("Unexpected tenant")
+	}
+	if tenant.FromContext(context.TODO()) != tenant.Default {
+		t.Fatalf("Default tenant was expected")
+	}
+}
+
+func TestValid(t *testing.T) {
+	for _, name := range []string{"shop-a", "42", "default"} {
+		if !tenant.Valid(name) {
+			t.Fatalf("%q was expected to be valid", name)
+		}
+	}
+	for _, name := range []string{"", "-shop", "shop:a", "cart_presence", "Shop", strings.Repeat("a", 64)} {
+		if tenant.Valid(name) {
+			t.Fatalf("%q was not expected to be valid", name)
+		}
+	}
+}
+
+func TestResolveHeader(t *testing.T) {
+	r := tenant.Resolver{Source: tenant.SourceHeader, Allowed: []string{tenant.Default, "shop-a"}}
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/1", nil)
+	if name, ok := r.Resolve(req); !ok || name != tenant.Default {
+		t.Fatalf("Default tenant was expected, got %q", name)
+	}
+
+	req.Header.Set(tenant.HeaderKey, "Shop-A")
+	if name, ok := r.Resolve(req); !ok || name != "shop-a" {
+		t.Fatalf("Unexpected tenant %q", name)
+	}
+
+	req.Header.Set(tenant.HeaderKey, "shop:a")
+	if _, ok := r.Resolve(req); ok {
+		t.Fatalf("Invalid tenant was not expected to resolve")
+	}
+}
+
+func TestResolveHeaderWithoutAllowed(t *testing.T) {
+	r := tenant.Resolver{Source: tenant.SourceHeader}
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/1", nil)
+	if name, ok := r.Resolve(req); !ok || name != tenant.Default {
+		t.Fatalf("Default tenant was expected, got %q", name)
+	}
+
+	req.Header.Set(tenant.HeaderKey, "shop-a")
+	if _, ok := r.Resolve(req); ok {
+		t.Fatalf("Tenants named by the client were not expected to resolve without an allow-list")
+	}
+	if _, ok := (tenant.Resolver{}).Check("shop-a"); ok {
+		t.Fatalf("Tenants named in metadata were not expected to resolve without an allow-list")
+	}
+}
+
+func TestResolveHost(t *testing.T) {
+	r := tenant.Resolver{Source: tenant.SourceHost}
+	hosts := map[string]string{
+		"shop-a.example.com:8080": "shop-a",
+		"shop-b.example.com":      "shop-b",
+		"localhost:8080":          tenant.Default,
+		"10.0.0.1:8080":           tenant.Default,
+	}
+	for host, expected := range hosts {
+		req, _ := http.NewRequest(http.MethodGet, "/v1/cart/1", nil)
+		req.Host = host
+		if name, ok := r.Resolve(req); !ok || name != expected {
+			t.Fatalf("%s: expected %q, got %q", host, expected, name)
+		}
+	}
+}
+
+func TestResolveAllowed(t *testing.T) {
+	r := tenant.Resolver{Allowed: []string{"shop-a"}}
+	if _, ok := r.Check("shop-b"); ok {
+		t.Fatalf("Not allowed tenant was not expected to resolve")
+	}
+	if name, ok := r.Check("shop-a"); !ok || name != "shop-a" {
+		t.Fatalf("Allowed tenant was expected to resolve")
+	}
+	if _, ok := r.Check(""); ok {
+		t.Fatalf("Default tenant was not expected to resolve unless allowed")
+	}
+
+	r.Allowed = append(r.Allowed, tenant.Default)
+	if name, ok := r.Check(""); !ok || name != tenant.Default {
+		t.Fatalf("Default tenant was expected to resolve")
+	}
+}
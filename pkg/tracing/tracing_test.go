@@ -0,0 +1,30 @@
+package tracing_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tracing"
+)
+
+func TestNewTracerProviderNone(t *testing.T) {
+	tp, shutdown, err := tracing.NewTracerProvider(context.TODO(), tracing.ExporterNone, "unit-testing")
+	if err != nil {
+		t.Fatalf("Error was not expected")
+	}
+	_, span := tp.Tracer("test").Start(context.TODO(), "span")
+	if !span.SpanContext().IsValid() {
+		t.Fatalf("Spans were expected to be created without an exporter")
+	}
+	span.End()
+	if err := shutdown(context.TODO()); err != nil {
+		t.Fatalf("Shutdown was not expected to fail")
+	}
+}
+
+func TestNewTracerProviderUnknown(t *testing.T) {
+	_, _, err := tracing.NewTracerProvider(context.TODO(), "zipkin", "unit-testing")
+	if err == nil {
+		t.Fatalf("Error was expected")
+	}
+}
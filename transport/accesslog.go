@@ -0,0 +1,47 @@
+package transport
+
+import (
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//accessLog writes one structured entry per request at the given level.
+//Only a sampleRate fraction (0 to 1) of the requests are logged, server errors are always logged.
+func accessLog(logger *logrus.Logger, level logrus.Level, sampleRate float64) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := newStatusRecorder(w)
+			next.ServeHTTP(rec, r)
+
+			if rec.status < http.StatusInternalServerError && !sampled(sampleRate) {
+				return
+			}
+			entryLevel := level
+			if rec.status >= http.StatusInternalServerError && entryLevel > logrus.ErrorLevel {
+				entryLevel = logrus.ErrorLevel
+			}
+			//the request ID is added from the context by the requestid hook
+			logger.WithContext(r.Context()).WithFields(logrus.Fields{
+				"method":      r.Method,
+				"route":       routeTemplate(r),
+				"path":        r.URL.Path,
+				"status":      rec.status,
+				"bytes":       rec.bytes,
+				"duration_ms": float64(time.Since(start).Microseconds()) / 1000,
+				"remote_addr": r.RemoteAddr,
+			}).Log(entryLevel, "access")
+		})
+	}
+}
+
+func sampled(rate float64) bool {
+	if rate >= 1 {
+		return true
+	}
+	return rand.Float64() < rate
+}
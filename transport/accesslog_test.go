@@ -0,0 +1,80 @@
+package transport_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	logtest "github.com/sirupsen/logrus/hooks/test"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAccessLogEntry(t *testing.T) {
+	logger, hook := logtest.NewNullLogger()
+	logger.AddHook(requestid.Hook{})
+	router := transport.NewHTTPRouter(logger, &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+	req.Header.Set(requestid.HeaderKey, "client-id")
+	req.RemoteAddr = "10.0.0.1:1234"
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(hook.Entries) != 1 {
+		t.Fatalf("Expected one access log entry, got %d", len(hook.Entries))
+	}
+	entry := hook.LastEntry()
+	if entry.Level != logrus.InfoLevel || entry.Message != "access" {
+		t.Fatalf("Unexpected entry %s %s", entry.Level, entry.Message)
+	}
+	expected := map[string]interface{}{
+		"method":           http.MethodGet,
+		"route":            "/v1/cart/{cart_id}",
+		"status":           http.StatusOK,
+		"remote_addr":      "10.0.0.1:1234",
+		requestid.LogField: "client-id",
+	}
+	for key, value := range expected {
+		if entry.Data[key] != value {
+			t.Fatalf("Unexpected %s: %v", key, entry.Data[key])
+		}
+	}
+	if entry.Data["bytes"].(int) == 0 {
+		t.Fatalf("Expected body size to be logged")
+	}
+}
+
+func TestAccessLogSampledOut(t *testing.T) {
+	os.Setenv(config.AccessLogSampleRateKey, "0")
+	defer os.Unsetenv(config.AccessLogSampleRateKey)
+
+	logger, hook := logtest.NewNullLogger()
+	router := transport.NewHTTPRouter(logger, &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(hook.Entries) != 0 {
+		t.Fatalf("Request was expected to be sampled out")
+	}
+}
+
+func TestAccessLogLevel(t *testing.T) {
+	os.Setenv(config.AccessLogLevelKey, "warn")
+	defer os.Unsetenv(config.AccessLogLevelKey)
+
+	logger, hook := logtest.NewNullLogger()
+	router := transport.NewHTTPRouter(logger, &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(hook.Entries) != 1 || hook.LastEntry().Level != logrus.WarnLevel {
+		t.Fatalf("Entry was expected at the configured level")
+	}
+}
@@ -0,0 +1,136 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+type request struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type handler struct {
+	svc    service.CartService
+	schema graphql.Schema
+}
+
+//NewHandler gives the http.Handler serving GraphQL queries over POST (JSON body) and GET (query string).
+//Mutations are only served over POST, a link or a cache must never change a cart.
+//The schema is static, so failing to build it is a programming error.
+func NewHandler(svc service.CartService) http.Handler {
+	schema, err := NewSchema(svc)
+	if err != nil {
+		panic("invalid graphql schema: " + err.Error())
+	}
+	return &handler{
+		svc:    svc,
+		schema: schema,
+	}
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	req := request{}
+	switch r.Method {
+	case http.MethodGet:
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				viewmodels.RespondWithError(w, r, viewmodels.Error{
+					Code:        viewmodels.ErrCodeBadRequest,
+					Description: viewmodels.ErrDescriptionBadRequestURL,
+					MessageKey:  viewmodels.MessageKeyBadRequestURL,
+				})
+				return
+			}
+		}
+		if isMutation(req) {
+			w.Header().Set("Allow", http.MethodPost)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(graphql.Result{Errors: []gqlerrors.FormattedError{{
+				Message:    "mutations must be sent with POST",
+				Extensions: map[string]interface{}{"code": viewmodels.ErrCodeBadRequest},
+			}}})
+			return
+		}
+	case http.MethodPost:
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			viewmodels.RespondWithError(w, r, viewmodels.StandardBadBodyRequest)
+			return
+		}
+	}
+
+	ctx := withItemLoader(r.Context(), newItemLoader(h.svc))
+	result := graphql.Do(graphql.Params{
+		Schema:         h.schema,
+		RequestString:  req.Query,
+		OperationName:  req.OperationName,
+		VariableValues: req.Variables,
+		Context:        ctx,
+	})
+	formatErrors(result.Errors)
+
+	//as per GraphQL over HTTP, errors come inside the result with a 200
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+//formatErrors puts back the extensions of the errors returned by thunks, graphql-go formats them without
+func formatErrors(errs []gqlerrors.FormattedError) {
+	for idx := range errs {
+		if errs[idx].Extensions == nil {
+			errs[idx].Extensions = extensionsOf(errs[idx].OriginalError())
+		}
+	}
+}
+
+//extensionsOf finds the extensions of the error graphql-go wrapped err around, nil when it has none
+func extensionsOf(err error) map[string]interface{} {
+	for err != nil {
+		switch e := err.(type) {
+		case gqlerrors.ExtendedError:
+			return e.Extensions()
+		case gqlerrors.FormattedError:
+			err = e.OriginalError()
+		case *gqlerrors.Error:
+			err = e.OriginalError
+		default:
+			return nil
+		}
+	}
+	return nil
+}
+
+//isMutation tells whether the operation the request runs is a mutation.
+//Queries that don't parse are not, graphql.Do reports their errors.
+func isMutation(req request) bool {
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return false
+	}
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if req.OperationName != "" && (op.Name == nil || op.Name.Value != req.OperationName) {
+			continue
+		}
+		if op.Operation == ast.OperationTypeMutation {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,276 @@
+package graphql_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/graphql"
+)
+
+type response struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func do(t *testing.T, svc *mockService, query string) response {
+	body, _ := json.Marshal(map[string]string{"query": query})
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader(body))
+	r := httptest.NewRecorder()
+	graphql.NewHandler(svc).ServeHTTP(r, req)
+
+	if r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	res := response{}
+	if err := json.NewDecoder(r.Body).Decode(&res); err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	return res
+}
+
+func TestCartAndCatalogInOneRoundTrip(t *testing.T) {
+	res := do(t, &mockService{}, `{ cart(id: "someCart") { id items { name quantity } } availableItems { id price } }`)
+	if len(res.Errors) != 0 {
+		t.Fatalf("Errors were not expected: %v", res.Errors)
+	}
+
+	cart := struct {
+		ID    string `json:"id"`
+		Items []map[string]interface{}
+	}{}
+	json.Unmarshal(res.Data["cart"], &cart)
+	if cart.ID != "someCart" || len(cart.Items) != 1 {
+		t.Fatalf("Unexpected cart: %s", res.Data["cart"])
+	}
+	if _, ok := cart.Items[0]["price"]; ok {
+		t.Fatalf("Price was not requested")
+	}
+
+	items := []map[string]interface{}{}
+	json.Unmarshal(res.Data["availableItems"], &items)
+	if len(items) != len(catalog) {
+		t.Fatalf("Unexpected items: %s", res.Data["availableItems"])
+	}
+}
+
+func TestItemLookupsAreBatched(t *testing.T) {
+	svc := &mockService{}
+	res := do(t, svc, `{ a: item(id: "1") { name } b: item(id: "2") { name } c: item(id: "1") { price } }`)
+	if len(res.Errors) != 0 {
+		t.Fatalf("Errors were not expected: %v", res.Errors)
+	}
+	if svc.getAllCalls != 0 || svc.getItemCalls != 2 {
+		t.Fatalf("Expected one GetItem per ID, got %d GetAvailableItems and %d GetItem", svc.getAllCalls, svc.getItemCalls)
+	}
+}
+
+func TestCartItemsGoThroughLoader(t *testing.T) {
+	svc := &mockService{storedItems: []models.Item{
+		{ID: "1", Quantity: 2},
+		{ID: "2", Quantity: 1},
+		{ID: "3", Quantity: 5},
+	}}
+	res := do(t, svc, `{ cart(id: "someCart") { items { id name price quantity } } other: cart(id: "otherCart") { items { name } } item(id: "2") { name } }`)
+	if len(res.Errors) != 0 {
+		t.Fatalf("Errors were not expected: %v", res.Errors)
+	}
+	if svc.getCalls != 0 || svc.getItemCalls != 3 {
+		t.Fatalf("Expected one GetItem per item, got %d GetCart and %d GetItem", svc.getCalls, svc.getItemCalls)
+	}
+
+	cart := struct {
+		Items []struct {
+			ID       string  `json:"id"`
+			Name     string  `json:"name"`
+			Price    float64 `json:"price"`
+			Quantity int     `json:"quantity"`
+		}
+	}{}
+	json.Unmarshal(res.Data["cart"], &cart)
+	if len(cart.Items) != 3 || cart.Items[2].ID != "3" || cart.Items[2].Name != "Third Item" || cart.Items[2].Quantity != 5 {
+		t.Fatalf("Unexpected cart: %s", res.Data["cart"])
+	}
+}
+
+func TestCartItemsNotRequested(t *testing.T) {
+	svc := &mockService{}
+	do(t, svc, `{ cart(id: "someCart") { id } }`)
+	if svc.getItemCalls != 0 {
+		t.Fatalf("Items were not requested, got %d GetItem", svc.getItemCalls)
+	}
+}
+
+func TestCartItemNotFound(t *testing.T) {
+	svc := &mockService{storedItems: []models.Item{{ID: "1", Quantity: 1}, {ID: "99", Quantity: 1}}}
+	res := do(t, svc, `{ cart(id: "someCart") { items { name } } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != serviceErrors.ItemNotFoundOnProviderCode {
+		t.Fatalf("Unexpected errors: %v", res.Errors)
+	}
+}
+
+func TestSingleItemLookup(t *testing.T) {
+	svc := &mockService{}
+	do(t, svc, `{ item(id: "1") { name } }`)
+	if svc.getAllCalls != 0 || svc.getItemCalls != 1 {
+		t.Fatalf("Expected a single GetItem, got %d GetAvailableItems and %d GetItem", svc.getAllCalls, svc.getItemCalls)
+	}
+}
+
+func TestBatchedItemNotFound(t *testing.T) {
+	res := do(t, &mockService{}, `{ a: item(id: "1") { name } b: item(id: "99") { name } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != serviceErrors.ItemNotFoundOnProviderCode {
+		t.Fatalf("Unexpected errors: %v", res.Errors)
+	}
+}
+
+func TestMutationErrorCarriesCode(t *testing.T) {
+	svc := &mockService{err: serviceErrors.ServiceError{Code: serviceErrors.ItemAlreadyInCartCode}}
+	res := do(t, svc, `mutation { addItemToCart(cartId: "someCart", itemId: "1", quantity: 2) { id } }`)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != serviceErrors.ItemAlreadyInCartCode {
+		t.Fatalf("Unexpected errors: %v", res.Errors)
+	}
+}
+
+func TestQueryOverGet(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(`{ cart(id: "someCart") { id } }`), nil)
+	r := httptest.NewRecorder()
+	graphql.NewHandler(&mockService{}).ServeHTTP(r, req)
+
+	res := response{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if len(res.Errors) != 0 || string(res.Data["cart"]) != `{"id":"someCart"}` {
+		t.Fatalf("Unexpected response: %v", res)
+	}
+}
+
+func TestMutationOverGetNotAllowed(t *testing.T) {
+	for operationName, query := range map[string]string{
+		"":  `mutation { createCart { id } }`,
+		"m": `query q { cart(id: "someCart") { id } } mutation m { createCart { id } }`,
+	} {
+		req, _ := http.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query)+"&operationName="+operationName, nil)
+		r := httptest.NewRecorder()
+		svc := &mockService{}
+		graphql.NewHandler(svc).ServeHTTP(r, req)
+
+		if r.Result().StatusCode != http.StatusMethodNotAllowed || r.Result().Header.Get("Allow") != http.MethodPost {
+			t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+		}
+		if svc.createCalls != 0 {
+			t.Fatalf("The mutation was not expected to run")
+		}
+	}
+}
+
+func TestQueryNextToMutationOverGet(t *testing.T) {
+	query := `query q { cart(id: "someCart") { id } } mutation m { createCart { id } }`
+	req, _ := http.NewRequest(http.MethodGet, "/graphql?query="+url.QueryEscape(query)+"&operationName=q", nil)
+	r := httptest.NewRecorder()
+	graphql.NewHandler(&mockService{}).ServeHTTP(r, req)
+
+	res := response{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if r.Result().StatusCode != http.StatusOK || string(res.Data["cart"]) != `{"id":"someCart"}` {
+		t.Fatalf("Unexpected response: %v", res)
+	}
+}
+
+func TestBadBody(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodPost, "/graphql", bytes.NewReader([]byte("badBody")))
+	r := httptest.NewRecorder()
+	graphql.NewHandler(&mockService{}).ServeHTTP(r, req)
+
+	if r.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+}
+
+// Mock service
+
+type mockService struct {
+	err         error
+	getAllCalls int
+	getCalls    int
+	createCalls int
+	//storedItems are the items of the stored carts, item 1 when empty
+	storedItems []models.Item
+
+	mu           sync.Mutex
+	getItemCalls int
+}
+
+var catalog = []models.Item{
+	{ID: "1", Name: "Some Item", Price: 12.34},
+	{ID: "2", Name: "Other Item", Price: 56.78},
+	{ID: "3", Name: "Third Item", Price: 9.99},
+}
+
+func (ms *mockService) cart(cartID string) (models.Cart, error) {
+	if ms.err != nil {
+		return models.Cart{}, ms.err
+	}
+	return models.Cart{
+		ID:    cartID,
+		Items: []models.Item{{ID: "1", Name: "Some Item", Quantity: 2, Price: 12.34}},
+	}, nil
+}
+
+func (ms *mockService) CreateCart(ctx context.Context) (models.Cart, error) {
+	ms.createCalls++
+	return ms.cart("newCart")
+}
+func (ms *mockService) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
+	ms.getCalls++
+	return ms.cart(cartID)
+}
+func (ms *mockService) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	if ms.err != nil {
+		return models.Cart{}, ms.err
+	}
+	items := ms.storedItems
+	if items == nil {
+		items = []models.Item{{ID: "1", Quantity: 2}}
+	}
+	return models.Cart{ID: cartID, Items: items}, nil
+}
+func (ms *mockService) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
+	ms.getAllCalls++
+	return catalog, ms.err
+}
+func (ms *mockService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	ms.mu.Lock()
+	ms.getItemCalls++
+	ms.mu.Unlock()
+	for _, item := range catalog {
+		if item.ID == id {
+			return item, ms.err
+		}
+	}
+	return models.Item{}, serviceErrors.ServiceError{Code: serviceErrors.ItemNotFoundOnProviderCode}
+}
+func (ms *mockService) AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) ModifyItemInCart(ctx context.Context, cartID, itemID string, newQuantity int) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteItemInCart(ctx context.Context, cartID, itemID string) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteAllItemsInCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteCart(ctx context.Context, cartID string) error {
+	return ms.err
+}
@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+)
+
+type loaderKey struct{}
+
+type itemResult struct {
+	item models.Item
+	err  error
+}
+
+//itemLoader batches provider item lookups made while resolving a single request.
+//Lookups are queued while the executor walks a level of the query and fetched together
+//the first time one of them is needed, an ID is looked up once however many fields ask for it.
+//Results are kept for the rest of the request.
+type itemLoader struct {
+	svc service.CartService
+
+	mu      sync.Mutex
+	pending []string
+	results map[string]*itemResult
+}
+
+func newItemLoader(svc service.CartService) *itemLoader {
+	return &itemLoader{
+		svc:     svc,
+		results: map[string]*itemResult{},
+	}
+}
+
+func withItemLoader(ctx context.Context, l *itemLoader) context.Context {
+	return context.WithValue(ctx, loaderKey{}, l)
+}
+
+func itemLoaderFrom(ctx context.Context, svc service.CartService) *itemLoader {
+	if l, ok := ctx.Value(loaderKey{}).(*itemLoader); ok {
+		return l
+	}
+	//no request scoped loader, nothing to batch with
+	return newItemLoader(svc)
+}
+
+//Load queues the item and returns a thunk the executor resolves later
+func (l *itemLoader) Load(ctx context.Context, id string) func() (interface{}, error) {
+	l.mu.Lock()
+	if _, ok := l.results[id]; !ok {
+		l.results[id] = nil
+		l.pending = append(l.pending, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.results[id] == nil {
+			l.dispatch(ctx)
+		}
+		res := l.results[id]
+		return res.item, res.err
+	}
+}
+
+//dispatch must be called holding the lock.
+//The provider has no batch lookup, the queued items are fetched concurrently, each with its own GetItem.
+func (l *itemLoader) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	results := make([]itemResult, len(keys))
+	wg := sync.WaitGroup{}
+	for i, key := range keys {
+		wg.Add(1)
+		go func(i int, key string) {
+			defer wg.Done()
+			item, err := l.svc.GetItem(ctx, key)
+			results[i] = itemResult{item: item, err: err}
+		}(i, key)
+	}
+	wg.Wait()
+	for i, key := range keys {
+		l.results[key] = &results[i]
+	}
+}
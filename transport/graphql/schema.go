@@ -0,0 +1,251 @@
+package graphql
+
+import (
+	"errors"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/graphql-go/graphql"
+)
+
+//resolverError exposes our error codes under the GraphQL error extensions
+type resolverError struct {
+	code string
+}
+
+func (e resolverError) Error() string {
+	return e.code
+}
+
+func (e resolverError) Extensions() map[string]interface{} {
+	return map[string]interface{}{
+		"code": e.code,
+	}
+}
+
+func errorFromService(err error) error {
+	sErr := &serviceErrors.ServiceError{}
+	if errors.As(err, sErr) {
+		return resolverError{code: sErr.Code}
+	}
+	vErr := &viewmodels.Error{}
+	if errors.As(err, vErr) {
+		return resolverError{code: vErr.Code}
+	}
+	return resolverError{code: viewmodels.ErrCodeInternalServerError}
+}
+
+var itemType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Item",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.ID),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Item).ID, nil
+			},
+		},
+		"name": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Item).Name, nil
+			},
+		},
+		"price": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.Float),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return p.Source.(models.Item).Price, nil
+			},
+		},
+		"quantity": &graphql.Field{
+			Type:        graphql.Int,
+			Description: "Only set for items inside a cart",
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				item := p.Source.(models.Item)
+				if item.Quantity == 0 {
+					return nil, nil
+				}
+				return item.Quantity, nil
+			},
+		},
+	},
+})
+
+//storedCart is a cart as stored, its items are looked up on the provider through the loader of the request
+//only when they are asked for
+type storedCart struct {
+	models.Cart
+	loader *itemLoader
+}
+
+//cartFrom gives the cart a Cart field resolves on, a storedCart or a models.Cart
+func cartFrom(source interface{}) models.Cart {
+	if stored, ok := source.(storedCart); ok {
+		return stored.Cart
+	}
+	return source.(models.Cart)
+}
+
+var cartType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Cart",
+	Fields: graphql.Fields{
+		"id": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.ID),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return cartFrom(p.Source).ID, nil
+			},
+		},
+		"items": &graphql.Field{
+			Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+			Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				stored, ok := p.Source.(storedCart)
+				if !ok {
+					items := p.Source.(models.Cart).Items
+					if items == nil {
+						return []models.Item{}, nil
+					}
+					return items, nil
+				}
+				thunks := make([]func() (interface{}, error), len(stored.Items))
+				for idx, item := range stored.Items {
+					thunks[idx] = stored.loader.Load(p.Context, item.ID)
+				}
+				return func() (interface{}, error) {
+					items := make([]models.Item, len(stored.Items))
+					for idx, thunk := range thunks {
+						extItem, err := thunk()
+						if err != nil {
+							return nil, errorFromService(err)
+						}
+						items[idx] = stored.Items[idx]
+						items[idx].Name = extItem.(models.Item).Name
+						items[idx].Price = extItem.(models.Item).Price
+					}
+					return items, nil
+				}, nil
+			},
+		},
+	},
+})
+
+//NewSchema gives the GraphQL schema backed by the CartService
+func NewSchema(svc service.CartService) (graphql.Schema, error) {
+	idArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	quantityArg := &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int)}
+
+	cartResult := func(cart models.Cart, err error) (interface{}, error) {
+		if err != nil {
+			return nil, errorFromService(err)
+		}
+		return cart, nil
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"cart": &graphql.Field{
+				Type: cartType,
+				Args: graphql.FieldConfigArgument{"id": idArg},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					cart, err := svc.GetStoredCart(p.Context, p.Args["id"].(string))
+					if err != nil {
+						return nil, errorFromService(err)
+					}
+					return storedCart{Cart: cart, loader: itemLoaderFrom(p.Context, svc)}, nil
+				},
+			},
+			"availableItems": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					items, err := svc.GetAvailableItems(p.Context)
+					if err != nil {
+						return nil, errorFromService(err)
+					}
+					return items, nil
+				},
+			},
+			"item": &graphql.Field{
+				Type: itemType,
+				Args: graphql.FieldConfigArgument{"id": idArg},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					thunk := itemLoaderFrom(p.Context, svc).Load(p.Context, p.Args["id"].(string))
+					return func() (interface{}, error) {
+						item, err := thunk()
+						if err != nil {
+							return nil, errorFromService(err)
+						}
+						return item, nil
+					}, nil
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createCart": &graphql.Field{
+				Type: graphql.NewNonNull(cartType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return cartResult(svc.CreateCart(p.Context))
+				},
+			},
+			"addItemToCart": &graphql.Field{
+				Type: graphql.NewNonNull(cartType),
+				Args: graphql.FieldConfigArgument{
+					"cartId":   idArg,
+					"itemId":   idArg,
+					"quantity": quantityArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return cartResult(svc.AddItemToCart(p.Context, p.Args["cartId"].(string), p.Args["itemId"].(string), p.Args["quantity"].(int)))
+				},
+			},
+			"modifyItemInCart": &graphql.Field{
+				Type: graphql.NewNonNull(cartType),
+				Args: graphql.FieldConfigArgument{
+					"cartId":   idArg,
+					"itemId":   idArg,
+					"quantity": quantityArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return cartResult(svc.ModifyItemInCart(p.Context, p.Args["cartId"].(string), p.Args["itemId"].(string), p.Args["quantity"].(int)))
+				},
+			},
+			"removeItemFromCart": &graphql.Field{
+				Type: graphql.NewNonNull(cartType),
+				Args: graphql.FieldConfigArgument{
+					"cartId": idArg,
+					"itemId": idArg,
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return cartResult(svc.DeleteItemInCart(p.Context, p.Args["cartId"].(string), p.Args["itemId"].(string)))
+				},
+			},
+			"removeAllItemsFromCart": &graphql.Field{
+				Type: graphql.NewNonNull(cartType),
+				Args: graphql.FieldConfigArgument{"cartId": idArg},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return cartResult(svc.DeleteAllItemsInCart(p.Context, p.Args["cartId"].(string)))
+				},
+			},
+			"deleteCart": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Args: graphql.FieldConfigArgument{"cartId": idArg},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := svc.DeleteCart(p.Context, p.Args["cartId"].(string)); err != nil {
+						return nil, errorFromService(err)
+					}
+					return true, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+}
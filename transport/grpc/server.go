@@ -0,0 +1,150 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc/cartpb"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative cartpb/cart.proto
+
+type cartServer struct {
+	cartpb.UnimplementedCartServiceServer
+	svc service.CartService
+}
+
+//NewGRPCServer gives a gRPC server exposing the CartService
+func NewGRPCServer(svc service.CartService, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	cartpb.RegisterCartServiceServer(s, &cartServer{svc: svc})
+	return s
+}
+
+func (s *cartServer) CreateCart(ctx context.Context, req *cartpb.CreateCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.CreateCart(ctx)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) GetCart(ctx context.Context, req *cartpb.GetCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.GetCart(ctx, req.GetCartId())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) GetAvailableItems(ctx context.Context, req *cartpb.GetAvailableItemsRequest) (*cartpb.GetAvailableItemsResponse, error) {
+	items, err := s.svc.GetAvailableItems(ctx)
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	res := &cartpb.GetAvailableItemsResponse{}
+	for _, item := range items {
+		res.Items = append(res.Items, itemToProto(item))
+	}
+	return res, nil
+}
+
+func (s *cartServer) GetItem(ctx context.Context, req *cartpb.GetItemRequest) (*cartpb.Item, error) {
+	item, err := s.svc.GetItem(ctx, req.GetItemId())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return itemToProto(item), nil
+}
+
+func (s *cartServer) AddItemToCart(ctx context.Context, req *cartpb.AddItemToCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.AddItemToCart(ctx, req.GetCartId(), req.GetItemId(), int(req.GetQuantity()))
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) ModifyItemInCart(ctx context.Context, req *cartpb.ModifyItemInCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.ModifyItemInCart(ctx, req.GetCartId(), req.GetItemId(), int(req.GetQuantity()))
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) DeleteItemInCart(ctx context.Context, req *cartpb.DeleteItemInCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.DeleteItemInCart(ctx, req.GetCartId(), req.GetItemId())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) DeleteAllItemsInCart(ctx context.Context, req *cartpb.DeleteAllItemsInCartRequest) (*cartpb.Cart, error) {
+	cart, err := s.svc.DeleteAllItemsInCart(ctx, req.GetCartId())
+	if err != nil {
+		return nil, statusFromError(err)
+	}
+	return cartToProto(cart), nil
+}
+
+func (s *cartServer) DeleteCart(ctx context.Context, req *cartpb.DeleteCartRequest) (*cartpb.DeleteCartResponse, error) {
+	if err := s.svc.DeleteCart(ctx, req.GetCartId()); err != nil {
+		return nil, statusFromError(err)
+	}
+	return &cartpb.DeleteCartResponse{}, nil
+}
+
+//statusCodes are the gRPC status codes error codes are answered with, the ones missing are internal errors
+var statusCodes = map[string]codes.Code{
+	serviceErrors.InternalCode:               codes.Internal,
+	serviceErrors.BadRequestCode:             codes.InvalidArgument,
+	serviceErrors.CartNotFoundCode:           codes.NotFound,
+	serviceErrors.ItemNotFoundCode:           codes.NotFound,
+	serviceErrors.ItemNotFoundOnProviderCode: codes.NotFound,
+	serviceErrors.ItemAlreadyInCartCode:      codes.AlreadyExists,
+	serviceErrors.ExternalApiErrorCode:       codes.Unavailable,
+	serviceErrors.CacheErrorCode:             codes.Internal,
+}
+
+//statusFromError maps service errors into the gRPC status codes of their code, the service code travels as the message
+func statusFromError(err error) error {
+	sErr := &serviceErrors.ServiceError{}
+	if errors.As(err, sErr) {
+		code, ok := statusCodes[sErr.Code]
+		if !ok {
+			code = codes.Internal
+		}
+		return status.Error(code, sErr.Code)
+	}
+	return status.Error(codes.Internal, viewmodels.ErrCodeInternalServerError)
+}
+
+func cartToProto(cart models.Cart) *cartpb.Cart {
+	pbCart := &cartpb.Cart{
+		Id:    cart.ID,
+		Items: []*cartpb.Item{},
+	}
+	for _, item := range cart.Items {
+		pbCart.Items = append(pbCart.Items, itemToProto(item))
+	}
+	return pbCart
+}
+
+func itemToProto(item models.Item) *cartpb.Item {
+	return &cartpb.Item{
+		Id:       item.ID,
+		Name:     item.Name,
+		Quantity: int32(item.Quantity),
+		Price:    item.Price,
+	}
+}
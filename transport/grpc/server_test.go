@@ -0,0 +1,148 @@
+package grpc_test
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	grpcTransport "github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc/cartpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestClient(t *testing.T, svc *mockService, opts ...grpc.ServerOption) cartpb.CartServiceClient {
+	lis := bufconn.Listen(1024 * 1024)
+	s := grpcTransport.NewGRPCServer(svc, opts...)
+	go s.Serve(lis)
+	t.Cleanup(s.Stop)
+
+	conn, err := grpc.DialContext(context.Background(), "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return cartpb.NewCartServiceClient(conn)
+}
+
+func TestGetCartOK(t *testing.T) {
+	client := newTestClient(t, &mockService{})
+
+	cart, err := client.GetCart(context.Background(), &cartpb.GetCartRequest{CartId: "someCart"})
+	if err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	if cart.GetId() != "someCart" || len(cart.GetItems()) != 1 || cart.GetItems()[0].GetQuantity() != 2 {
+		t.Fatalf("Unexpected cart: %v", cart)
+	}
+}
+
+func TestGetAvailableItemsOK(t *testing.T) {
+	client := newTestClient(t, &mockService{})
+
+	res, err := client.GetAvailableItems(context.Background(), &cartpb.GetAvailableItemsRequest{})
+	if err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	if len(res.GetItems()) != 1 {
+		t.Fatalf("Unexpected items: %v", res.GetItems())
+	}
+}
+
+func TestDeleteCartOK(t *testing.T) {
+	client := newTestClient(t, &mockService{})
+
+	if _, err := client.DeleteCart(context.Background(), &cartpb.DeleteCartRequest{CartId: "someCart"}); err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+}
+
+func TestErrorMapping(t *testing.T) {
+	tests := []struct {
+		err  error
+		code codes.Code
+	}{
+		{serviceErrors.ServiceError{Code: serviceErrors.CartNotFoundCode}, codes.NotFound},
+		{serviceErrors.ServiceError{Code: serviceErrors.ItemNotFoundCode}, codes.NotFound},
+		{serviceErrors.ServiceError{Code: serviceErrors.ItemNotFoundOnProviderCode}, codes.NotFound},
+		{serviceErrors.ServiceError{Code: serviceErrors.ItemAlreadyInCartCode}, codes.AlreadyExists},
+		{serviceErrors.ServiceError{Code: serviceErrors.ExternalApiErrorCode}, codes.Unavailable},
+		{serviceErrors.ServiceError{Code: serviceErrors.CacheErrorCode}, codes.Internal},
+		{fmt.Errorf("some error"), codes.Internal},
+	}
+	for _, tt := range tests {
+		client := newTestClient(t, &mockService{err: tt.err})
+
+		_, err := client.AddItemToCart(context.Background(), &cartpb.AddItemToCartRequest{CartId: "someCart", ItemId: "1", Quantity: 1})
+		if status.Code(err) != tt.code {
+			t.Fatalf("Unexpected code for %s: %s", tt.err, status.Code(err))
+		}
+	}
+}
+
+// Mock service
+
+type mockService struct {
+	err    error
+	tenant string
+}
+
+func (ms *mockService) cart(cartID string) (models.Cart, error) {
+	if ms.err != nil {
+		return models.Cart{}, ms.err
+	}
+	return models.Cart{
+		ID:    cartID,
+		Items: []models.Item{{ID: "1", Name: "Some Item", Quantity: 2, Price: 12.34}},
+	}, nil
+}
+
+func (ms *mockService) CreateCart(ctx context.Context) (models.Cart, error) {
+	return ms.cart("newCart")
+}
+func (ms *mockService) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
+	ms.tenant = tenant.FromContext(ctx)
+	return ms.cart(cartID)
+}
+func (ms *mockService) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
+	cart, err := ms.cart("")
+	return cart.Items, err
+}
+func (ms *mockService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	cart, err := ms.cart("")
+	if err != nil {
+		return models.Item{}, err
+	}
+	return cart.Items[0], nil
+}
+func (ms *mockService) AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) ModifyItemInCart(ctx context.Context, cartID, itemID string, newQuantity int) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteItemInCart(ctx context.Context, cartID, itemID string) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteAllItemsInCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return ms.cart(cartID)
+}
+func (ms *mockService) DeleteCart(ctx context.Context, cartID string) error {
+	return ms.err
+}
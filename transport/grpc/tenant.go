@@ -0,0 +1,31 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+//TenantInterceptor puts the tenant of the x-tenant-id metadata in the context of every call,
+//so gRPC callers get the same isolation as HTTP ones
+func TenantInterceptor(resolver tenant.Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		name := ""
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(tenant.MetadataKey); len(values) > 0 {
+				name = values[0]
+			}
+		}
+		t, ok := resolver.Check(name)
+		if !ok {
+			return nil, status.Error(codes.InvalidArgument, viewmodels.ErrCodeBadRequest)
+		}
+		return handler(tenant.NewContext(ctx, t), req)
+	}
+}
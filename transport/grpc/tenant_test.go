@@ -0,0 +1,41 @@
+package grpc_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	grpcTransport "github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/grpc/cartpb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestTenantInterceptor(t *testing.T) {
+	svc := &mockService{}
+	client := newTestClient(t, svc, grpc.UnaryInterceptor(grpcTransport.TenantInterceptor(tenant.Resolver{
+		Allowed: []string{"shop-a"},
+	})))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), tenant.MetadataKey, "shop-a")
+	if _, err := client.GetCart(ctx, &cartpb.GetCartRequest{CartId: "cart1"}); err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	if svc.tenant != "shop-a" {
+		t.Fatalf("Unexpected tenant %q", svc.tenant)
+	}
+
+	_, err := client.GetCart(context.Background(), &cartpb.GetCartRequest{CartId: "cart1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Calls without tenant were expected to be rejected when the default one is not allowed, got %v", err)
+	}
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), tenant.MetadataKey, "shop-b")
+	_, err = client.GetCart(ctx, &cartpb.GetCartRequest{CartId: "cart1"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("Not allowed tenant was expected to be rejected, got %v", err)
+	}
+}
@@ -3,29 +3,89 @@ package transport
 import (
 	"net/http"
 
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/controller"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/oas"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport/graphql"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
-func NewHTTPRouter(svc service.CartService, hsvc health.Service) *mux.Router {
+func NewHTTPRouter(logger *logrus.Logger, svc service.CartService, hsvc health.Service, broker collab.Broker, reg *prometheus.Registry, tp trace.TracerProvider) *mux.Router {
 	cc := controller.CartController{
 		Service: svc,
+		Logger:  logger,
 	}
 
 	ic := controller.ItemController{
 		Service: svc,
+		Logger:  logger,
 	}
 
 	hc := controller.HealthController{
 		Service: hsvc,
 	}
 
+	colc := controller.CollabController{
+		Service: svc,
+		Broker:  broker,
+		Logger:  logger,
+	}
+
 	r := mux.NewRouter()
+	r.Use(requestID)
+	r.Use(tenantScope(logger, tenant.Resolver{
+		Source:  config.GetTenantSource(),
+		Allowed: config.GetTenants(),
+	}))
+	r.Use(accessLog(logger, config.GetAccessLogLevel(), config.GetAccessLogSampleRate()))
+	r.Use(newHTTPMetrics(reg).middleware)
+	r.Use(tracing(tp))
+	//validating responses checks the requests as well
+	if config.GetOpenAPIValidation() || config.GetOpenAPIValidateResponses() {
+		validator, err := oas.NewValidator()
+		if err != nil {
+			logger.WithError(err).Fatal("openapi_spec_error")
+		}
+		r.Use(openAPIValidation(validator, config.GetOpenAPIValidateResponses()))
+	}
+
+	r.Handle("/metrics", promhttp.HandlerFor(reg, promhttp.HandlerOpts{})).Methods(http.MethodGet)
+
+	//GraphQL
+	r.Handle("/graphql", graphql.NewHandler(svc)).Methods(http.MethodGet, http.MethodPost)
+
+	//Documentation, embedded in the binary
+	r.Handle("/openapi.yml", oas.SpecHandler()).Methods(http.MethodGet)
+	r.Handle("/swagger", http.RedirectHandler("/swagger/", http.StatusMovedPermanently)).Methods(http.MethodGet)
+	r.PathPrefix("/swagger/").Handler(http.StripPrefix("/swagger/", oas.UIHandler())).Methods(http.MethodGet)
+
+	v1 := r.PathPrefix("/v1").Subrouter()
+	registerV1Routes(v1, cc, ic, hc, colc)
 
+	//Unversioned routes are kept as aliases of v1 until the sunset date
+	legacy := r.NewRoute().Subrouter()
+	legacy.Use(deprecated("/v1", config.GetLegacyRoutesSunset()))
+	registerV1Routes(legacy, cc, ic, hc, colc)
+
+	return r
+}
+
+//registerV1Routes mounts the v1 API on the given router.
+//A new version gets its own register function, so it can use different controllers and viewmodels.
+func registerV1Routes(r *mux.Router, cc controller.CartController, ic controller.ItemController, hc controller.HealthController, colc controller.CollabController) {
 	r.HandleFunc("/health", hc.Health).Methods(http.MethodGet)
+	r.HandleFunc("/health/live", hc.Live).Methods(http.MethodGet)
+	r.HandleFunc("/health/ready", hc.Ready).Methods(http.MethodGet)
+	r.HandleFunc("/health/history", hc.History).Methods(http.MethodGet)
 
 	//Cart Endpoints
 	r.HandleFunc("/cart", cc.CreateCart).Methods(http.MethodPost)
@@ -38,10 +98,10 @@ func NewHTTPRouter(svc service.CartService, hsvc health.Service) *mux.Router {
 	r.HandleFunc("/cart/{cart_id}/item/all", cc.RemoveAllItems).Methods(http.MethodDelete)
 	r.HandleFunc("/cart/{cart_id}/item/{item_id:[0-9]+}", cc.RemoveItem).Methods(http.MethodDelete)
 
+	//Shared Cart WebSocket
+	r.HandleFunc("/cart/{cart_id}/ws", colc.SharedCart).Methods(http.MethodGet)
+
 	//Items Endpoints
 	r.HandleFunc("/items/available", ic.GetAllItems).Methods(http.MethodGet)
 	r.HandleFunc("/items/{item_id}", ic.GetItem).Methods(http.MethodGet)
-
-	r.PathPrefix("/swagger").Handler(http.StripPrefix("/swagger", http.FileServer(http.Dir("./swagger"))))
-	return r
 }
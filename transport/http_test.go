@@ -0,0 +1,258 @@
+package transport_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/health"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/models"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/gorilla/websocket"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func serve(method, path string) *httptest.ResponseRecorder {
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	r := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, nil)
+	router.ServeHTTP(r, req)
+	return r
+}
+
+func TestV1Routes(t *testing.T) {
+	r := serve(http.MethodGet, "/v1/cart/someCart")
+
+	if r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	if r.Header().Get("Deprecation") != "" || r.Header().Get("Sunset") != "" {
+		t.Fatalf("v1 routes are not deprecated")
+	}
+}
+
+func TestLegacyRoutesAreDeprecated(t *testing.T) {
+	r := serve(http.MethodGet, "/cart/someCart")
+
+	if r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	if r.Header().Get("Deprecation") != "true" {
+		t.Fatalf("Deprecation header was expected")
+	}
+	if r.Header().Get("Sunset") == "" {
+		t.Fatalf("Sunset header was expected")
+	}
+	if r.Header().Get("Link") != `</v1/cart/someCart>; rel="successor-version"` {
+		t.Fatalf("Unexpected Link header: %s", r.Header().Get("Link"))
+	}
+}
+
+func TestLegacyMethodNotAllowed(t *testing.T) {
+	r := serve(http.MethodPatch, "/cart/someCart")
+
+	if r.Result().StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+}
+
+func TestUnknownRoute(t *testing.T) {
+	r := serve(http.MethodGet, "/v2/cart/someCart")
+
+	if r.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	r := serve(http.MethodGet, "/v1/cart/someCart")
+
+	id := r.Header().Get(requestid.HeaderKey)
+	if !requestid.Valid(id) {
+		t.Fatalf("A request ID was expected")
+	}
+	res := viewmodels.BaseResponse{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if res.Meta.RequestID != id {
+		t.Fatalf("Request ID was expected in meta")
+	}
+}
+
+func TestRequestIDEchoed(t *testing.T) {
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	r := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+	req.Header.Set(requestid.HeaderKey, "client-id")
+	router.ServeHTTP(r, req)
+
+	if r.Header().Get(requestid.HeaderKey) != "client-id" {
+		t.Fatalf("Request ID was expected to be echoed")
+	}
+}
+
+func TestTraceparentContinued(t *testing.T) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	sr := tracetest.NewSpanRecorder()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr))
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), tp)
+
+	r := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(r, req)
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Expected one server span, got %d", len(spans))
+	}
+	if spans[0].Name() != "GET /v1/cart/{cart_id}" {
+		t.Fatalf("Unexpected span name %s", spans[0].Name())
+	}
+	if spans[0].SpanContext().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("Inbound trace was expected to be continued")
+	}
+	if spans[0].Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Fatalf("Inbound span was expected as parent")
+	}
+}
+
+func TestMetricsByRouteTemplate(t *testing.T) {
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	for _, path := range []string{"/v1/cart/one", "/v1/cart/two", "/metrics"} {
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	r := httptest.NewRecorder()
+	req, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	router.ServeHTTP(r, req)
+
+	body := r.Body.String()
+	if !strings.Contains(body, `http_requests_total{code="200",method="GET",route="/v1/cart/{cart_id}"} 2`) {
+		t.Fatalf("Requests were expected to be counted by route template:\n%s", body)
+	}
+	if !strings.Contains(body, `http_requests_in_flight{method="GET",route="/metrics"} 1`) {
+		t.Fatalf("In flight gauge was expected:\n%s", body)
+	}
+	if !strings.Contains(body, `http_request_duration_seconds_count{code="200",method="GET",route="/v1/cart/{cart_id}"} 2`) {
+		t.Fatalf("Durations were expected:\n%s", body)
+	}
+}
+
+func TestSharedCartThroughMiddlewares(t *testing.T) {
+	srv := httptest.NewServer(transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider()))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/v1/cart/someCart/ws", nil)
+	if err != nil {
+		t.Fatalf("Error was not expected: %s", err)
+	}
+	conn.Close()
+}
+
+//******** Health Service Mock
+
+type healthMock struct{}
+
+func (hm *healthMock) Check(ctx context.Context) health.Report {
+	return health.Report{Status: health.StatusUp}
+}
+
+func (hm *healthMock) History() []health.Transition {
+	return nil
+}
+
+// Mock service
+
+type mockService struct {
+	//deleteErr is what DeleteCart fails with
+	deleteErr error
+}
+
+func (ms *mockService) CreateCart(ctx context.Context) (models.Cart, error) {
+	return models.Cart{}, nil
+}
+func (ms *mockService) GetCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return models.Cart{ID: cartID}, nil
+}
+func (ms *mockService) GetStoredCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return models.Cart{ID: cartID}, nil
+}
+func (ms *mockService) GetAvailableItems(ctx context.Context) ([]models.Item, error) {
+	return []models.Item{}, nil
+}
+func (ms *mockService) GetItem(ctx context.Context, id string) (models.Item, error) {
+	return models.Item{}, nil
+}
+func (ms *mockService) AddItemToCart(ctx context.Context, cartID, itemID string, quantity int) (models.Cart, error) {
+	return models.Cart{}, nil
+}
+func (ms *mockService) ModifyItemInCart(ctx context.Context, cartID, itemID string, newQuantity int) (models.Cart, error) {
+	return models.Cart{}, nil
+}
+func (ms *mockService) DeleteItemInCart(ctx context.Context, cartID, itemID string) (models.Cart, error) {
+	return models.Cart{}, nil
+}
+func (ms *mockService) DeleteAllItemsInCart(ctx context.Context, cartID string) (models.Cart, error) {
+	return models.Cart{}, nil
+}
+func (ms *mockService) DeleteCart(ctx context.Context, cartID string) error {
+	return ms.deleteErr
+}
+
+func TestTenantRejected(t *testing.T) {
+	os.Setenv(config.TenantsKey, "shop-a")
+	defer os.Unsetenv(config.TenantsKey)
+
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	for name, expected := range map[string]int{"shop-a": http.StatusOK, "shop-b": http.StatusBadRequest, "": http.StatusBadRequest} {
+		r := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, "/v1/cart/someCart", nil)
+		req.Header.Set(tenant.HeaderKey, name)
+		router.ServeHTTP(r, req)
+
+		if r.Result().StatusCode != expected {
+			t.Fatalf("Tenant %q: unexpected Status Code %d", name, r.Result().StatusCode)
+		}
+	}
+}
+
+func TestUnscopedRoutesWithoutTenant(t *testing.T) {
+	os.Setenv(config.TenantsKey, "shop-a")
+	defer os.Unsetenv(config.TenantsKey)
+
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	for _, path := range []string{"/v1/health/live", "/health/ready", "/metrics", "/openapi.yml"} {
+		r := httptest.NewRecorder()
+		req, _ := http.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(r, req)
+
+		if r.Result().StatusCode != http.StatusOK {
+			t.Fatalf("%s: unexpected Status Code %d", path, r.Result().StatusCode)
+		}
+	}
+}
+
+func TestHealthProbes(t *testing.T) {
+	for _, path := range []string{"/v1/health", "/v1/health/live", "/v1/health/ready", "/v1/health/history", "/health/ready"} {
+		if r := serve(http.MethodGet, path); r.Result().StatusCode != http.StatusOK {
+			t.Fatalf("%s: unexpected Status Code %d", path, r.Result().StatusCode)
+		}
+	}
+}
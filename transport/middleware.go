@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/tenant"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/gorilla/mux"
+	"github.com/sirupsen/logrus"
+)
+
+//deprecated flags the responses of a route as deprecated (draft-ietf-httpapi-deprecation-header),
+//announces when it goes away (RFC 8594) and links to the same route under the successor prefix
+func deprecated(successorPrefix string, sunset time.Time) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Deprecation", "true")
+			w.Header().Set("Sunset", sunset.UTC().Format(http.TimeFormat))
+			w.Header().Set("Link", fmt.Sprintf(`<%s%s>; rel="successor-version"`, successorPrefix, r.URL.Path))
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+//requestID makes sure every request carries an ID, taken from X-Request-ID when valid or generated otherwise.
+//The ID travels in the request context and is echoed back in the response.
+func requestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestid.HeaderKey)
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+		w.Header().Set(requestid.HeaderKey, id)
+		next.ServeHTTP(w, r.WithContext(requestid.NewContext(r.Context(), id)))
+	})
+}
+
+//tenantScope puts the tenant of the request in its context, every cart lookup is scoped to it.
+//Requests with an invalid or not allowed tenant are rejected, unless the route holds no tenant data.
+func tenantScope(logger *logrus.Logger, resolver tenant.Resolver) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			t, ok := resolver.Resolve(r)
+			if !ok && unscoped(routeTemplate(r)) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if !ok {
+				logger.WithContext(r.Context()).WithField("host", r.Host).Warn("tenant_rejected")
+				viewmodels.RespondWithError(w, r, viewmodels.StandardBadTenantRequest)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(tenant.NewContext(r.Context(), t)))
+		})
+	}
+}
+
+//unscoped tells whether a route holds no tenant data, probes and scrapers call them without naming a tenant
+func unscoped(template string) bool {
+	template = strings.TrimPrefix(template, "/v1")
+	return strings.HasPrefix(template, "/health") ||
+		strings.HasPrefix(template, "/swagger") ||
+		template == "/metrics" ||
+		template == "/openapi.yml"
+}
+
+//statusRecorder keeps the status code and body size written by the handler.
+//It lets hijacking through, the shared cart WebSocket needs it.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func newStatusRecorder(w http.ResponseWriter) *statusRecorder {
+	return &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	s.status = status
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	n, err := s.ResponseWriter.Write(b)
+	s.bytes += n
+	return n, err
+}
+
+func (s *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := s.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	//a hijacked connection switches protocols
+	s.status = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+//routeTemplate is the gorilla route template of the matched route, so IDs don't blow up label cardinality
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			return tpl
+		}
+	}
+	return "unmatched"
+}
@@ -0,0 +1,139 @@
+package transport_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/oas"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSpecIsValid(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData(oas.Spec)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("Unexpected invalid spec: %v", err)
+	}
+}
+
+//undocumented are the routes deliberately left out of the spec: they are not part of the REST API
+var undocumented = map[string]bool{
+	"GET /metrics":     true,
+	"GET /graphql":     true,
+	"POST /graphql":    true,
+	"GET /openapi.yml": true,
+	"GET /swagger":     true,
+	"GET /swagger/":    true,
+}
+
+//TestRoutesMatchSpec fails when a route is missing from the spec or the spec has an operation with no route.
+//Routes out of the spec are either listed in undocumented or unversioned aliases of a documented v1 route.
+func TestRoutesMatchSpec(t *testing.T) {
+	router := transport.NewHTTPRouter(logrus.New(), &mockService{}, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+	routes := map[string]bool{}
+	err := router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			//subrouters have no methods
+			return nil
+		}
+		for _, method := range methods {
+			routes[fmt.Sprintf("%s %s", method, oas.PathOf(template))] = true
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error walking the router: %v", err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(oas.Spec)
+	if err != nil {
+		t.Fatalf("Unexpected error loading the spec: %v", err)
+	}
+	documented := map[string]bool{}
+	for path, item := range doc.Paths {
+		for method := range item.Operations() {
+			documented[fmt.Sprintf("%s %s", method, path)] = true
+		}
+	}
+
+	missing, unrouted, stale := []string{}, []string{}, []string{}
+	for route := range routes {
+		if documented[route] || undocumented[route] {
+			continue
+		}
+		//the legacy alias of "GET /v1/cart/{cart_id}" is "GET /cart/{cart_id}"
+		method, path, _ := strings.Cut(route, " ")
+		if !strings.HasPrefix(path, "/v1/") && documented[method+" /v1"+path] {
+			continue
+		}
+		missing = append(missing, route)
+	}
+	for operation := range documented {
+		if !routes[operation] {
+			unrouted = append(unrouted, operation)
+		}
+	}
+	for route := range undocumented {
+		if !routes[route] {
+			stale = append(stale, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(unrouted)
+	sort.Strings(stale)
+	if len(missing) > 0 {
+		t.Errorf("Routes missing from oas/oas.yml, document them or list them as undocumented: %v", missing)
+	}
+	if len(unrouted) > 0 {
+		t.Errorf("Operations of oas/oas.yml with no route: %v", unrouted)
+	}
+	if len(stale) > 0 {
+		t.Errorf("Undocumented routes that no longer exist: %v", stale)
+	}
+}
+
+func TestSpecServed(t *testing.T) {
+	r := serve(http.MethodGet, "/openapi.yml")
+
+	if r.Result().StatusCode != http.StatusOK || r.Header().Get("Content-Type") != oas.SpecContentType {
+		t.Fatalf("Unexpected response %d %s", r.Result().StatusCode, r.Header().Get("Content-Type"))
+	}
+	if r.Body.String() != string(oas.Spec) {
+		t.Fatalf("Unexpected spec served")
+	}
+}
+
+func TestSwaggerUIServed(t *testing.T) {
+	r := serve(http.MethodGet, "/swagger")
+	if r.Result().StatusCode != http.StatusMovedPermanently || r.Header().Get("Location") != "/swagger/" {
+		t.Fatalf("Unexpected redirect %d %s", r.Result().StatusCode, r.Header().Get("Location"))
+	}
+
+	r = serve(http.MethodGet, "/swagger/")
+	if r.Result().StatusCode != http.StatusOK || !strings.Contains(r.Body.String(), "swagger-ui") {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+
+	r = serve(http.MethodGet, "/swagger/swagger-initializer.js")
+	if r.Result().StatusCode != http.StatusOK || !strings.Contains(r.Body.String(), `"/openapi.yml"`) {
+		t.Fatalf("The UI is expected to load the embedded spec")
+	}
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/oas"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+//openAPIValidation rejects requests not matching the OpenAPI spec before they reach the controllers,
+//listing every broken rule. With validateResponses the responses are checked too, and one not matching
+//the spec panics: it is a bug of the service, meant to be caught by tests and during development.
+//Routes the spec does not document are let through.
+func openAPIValidation(validator *oas.Validator, validateResponses bool) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			template := routeTemplate(r)
+			vars := mux.Vars(r)
+			err := validator.ValidateRequest(r, template, vars)
+			if errors.Is(err, oas.ErrNotDocumented) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if err != nil {
+				viewmodels.RespondWithError(w, r, viewmodels.Error{
+					Code:        viewmodels.ErrCodeBadRequest,
+					Description: viewmodels.ErrDescriptionBadRequestSpec,
+					Details:     violationsFromSpec(err),
+					MessageKey:  viewmodels.MessageKeyBadRequestSpec,
+				})
+				return
+			}
+			//the shared cart switches protocols, there is no response to check
+			if !validateResponses || websocket.IsWebSocketUpgrade(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			buffered := &bufferedResponse{ResponseWriter: w}
+			next.ServeHTTP(buffered, r)
+			if err := validator.ValidateResponse(r, template, vars, buffered.statusCode(), w.Header(), buffered.body.Bytes()); err != nil {
+				panic(fmt.Sprintf("response does not match the OpenAPI spec: %v", err))
+			}
+			w.WriteHeader(buffered.statusCode())
+			w.Write(buffered.body.Bytes())
+		})
+	}
+}
+
+//bufferedResponse holds the response until it is checked, headers go straight to the ResponseWriter
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (b *bufferedResponse) WriteHeader(status int) {
+	if b.status == 0 {
+		b.status = status
+	}
+}
+
+func (b *bufferedResponse) Write(p []byte) (int, error) {
+	return b.body.Write(p)
+}
+
+func (b *bufferedResponse) statusCode() int {
+	if b.status == 0 {
+		return http.StatusOK
+	}
+	return b.status
+}
+
+//violationsFromSpec lists the rules of the spec a request broke
+func violationsFromSpec(err error) []viewmodels.Violation {
+	errs := []error{err}
+	if multi, ok := err.(openapi3.MultiError); ok {
+		errs = multi
+	}
+	violations := []viewmodels.Violation{}
+	for _, err := range errs {
+		reqErr, ok := err.(*openapi3filter.RequestError)
+		if !ok {
+			violations = append(violations, viewmodels.Violation{Rule: viewmodels.RuleSchema, Message: err.Error()})
+			continue
+		}
+		parameter := ""
+		if reqErr.Parameter != nil {
+			parameter = reqErr.Parameter.Name
+		}
+		schemaErrs := schemaErrors(reqErr.Err)
+		for _, schemaErr := range schemaErrs {
+			v := violationFromSchema(schemaErr)
+			if parameter != "" {
+				v.Pointer, v.Parameter = "", parameter
+			}
+			violations = append(violations, v)
+		}
+		if len(schemaErrs) > 0 {
+			continue
+		}
+
+		v := viewmodels.Violation{Parameter: parameter, Rule: viewmodels.RuleSchema, Message: reqErr.Reason}
+		switch {
+		case errors.Is(reqErr.Err, openapi3filter.ErrInvalidRequired):
+			v.Rule, v.Message = viewmodels.RuleRequired, "is required"
+		case reqErr.RequestBody != nil && reqErr.Err != nil:
+			//the body could not be decoded
+			v.Rule, v.Message = viewmodels.RuleSyntax, "is not valid JSON"
+		case v.Message == "":
+			v.Message = reqErr.Error()
+		}
+		violations = append(violations, v)
+	}
+	return violations
+}
+
+//schemaErrors are the schema rules broken by a value, none when err is not about the schema
+func schemaErrors(err error) []*openapi3.SchemaError {
+	switch e := err.(type) {
+	case *openapi3.SchemaError:
+		return []*openapi3.SchemaError{e}
+	case openapi3.MultiError:
+		list := []*openapi3.SchemaError{}
+		for _, inner := range e {
+			list = append(list, schemaErrors(inner)...)
+		}
+		return list
+	}
+	return nil
+}
+
+func violationFromSchema(err *openapi3.SchemaError) viewmodels.Violation {
+	tokens := err.JSONPointer()
+	v := viewmodels.Violation{Rule: viewmodels.RuleSchema, Message: err.Reason}
+	switch err.SchemaField {
+	case "required":
+		v.Rule, v.Message = viewmodels.RuleRequired, "is required"
+	case "type":
+		v.Rule = viewmodels.RuleType
+	case "minimum", "exclusiveMinimum", "minLength", "minItems":
+		v.Rule = viewmodels.RuleMin
+	case "properties":
+		//the error is about the object, the reason names the property it does not know
+		name := strings.TrimSuffix(strings.TrimPrefix(err.Reason, "property "), " is unsupported")
+		if unquoted, uErr := strconv.Unquote(name); uErr == nil {
+			tokens = append(tokens, unquoted)
+			v.Rule, v.Message = viewmodels.RuleUnknown, "is not a known field"
+		}
+	}
+	v.Pointer = viewmodels.Pointer(tokens...)
+	return v
+}
@@ -0,0 +1,126 @@
+package transport_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/collab"
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/service"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/transport"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func validatingRouter(t *testing.T, key string, svc service.CartService) *mux.Router {
+	os.Setenv(key, "true")
+	defer os.Unsetenv(key)
+	return transport.NewHTTPRouter(logrus.New(), svc, &healthMock{}, collab.NewMemoryBroker(), prometheus.NewRegistry(), trace.NewNoopTracerProvider())
+}
+
+func serveJSON(router http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(r, req)
+	return r
+}
+
+func TestRequestsValidatedAgainstSpec(t *testing.T) {
+	router := validatingRouter(t, config.OpenAPIValidationKey, &mockService{})
+	r := serveJSON(router, http.MethodPost, "/v1/cart/someCart/item", `{"quantity":0,"colour":"red"}`)
+
+	if r.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	res := struct {
+		Error viewmodels.Error `json:"error"`
+	}{}
+	json.NewDecoder(r.Body).Decode(&res)
+	if res.Error.Code != viewmodels.ErrCodeBadRequest || res.Error.Description != viewmodels.ErrDescriptionBadRequestSpec {
+		t.Fatalf("Unexpected error %+v", res.Error)
+	}
+	rules := map[string]string{}
+	for _, v := range res.Error.Details {
+		rules[v.Pointer] = v.Rule
+	}
+	expected := map[string]string{
+		"/colour":   viewmodels.RuleUnknown,
+		"/id":       viewmodels.RuleRequired,
+		"/quantity": viewmodels.RuleMin,
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("Unexpected violations %+v", res.Error.Details)
+	}
+}
+
+func TestRequestBodyValidatedAgainstSpec(t *testing.T) {
+	router := validatingRouter(t, config.OpenAPIValidationKey, &mockService{})
+	for body, rule := range map[string]string{
+		"":                 viewmodels.RuleRequired,
+		"badBody":          viewmodels.RuleSyntax,
+		`{"quantity":"2"}`: viewmodels.RuleType,
+	} {
+		r := serveJSON(router, http.MethodPut, "/v1/cart/someCart/item/1", body)
+
+		res := struct {
+			Error viewmodels.Error `json:"error"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&res)
+		if r.Result().StatusCode != http.StatusBadRequest || len(res.Error.Details) != 1 || res.Error.Details[0].Rule != rule {
+			t.Fatalf("Body %q: unexpected response %d %+v", body, r.Result().StatusCode, res.Error)
+		}
+	}
+}
+
+func TestValidRequestsReachControllers(t *testing.T) {
+	router := validatingRouter(t, config.OpenAPIValidationKey, &mockService{})
+
+	if r := serveJSON(router, http.MethodPost, "/v1/cart/someCart/item", `{"id":"1","quantity":2}`); r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	//legacy aliases are documented by the same operations
+	if r := serveJSON(router, http.MethodPost, "/cart/someCart/item", `{"id":"1"}`); r.Result().StatusCode != http.StatusBadRequest {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+	//routes the spec does not document are let through
+	if r := serveJSON(router, http.MethodGet, "/metrics", ""); r.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Unexpected Status Code: %d", r.Result().StatusCode)
+	}
+}
+
+func TestResponsesValidatedAgainstSpec(t *testing.T) {
+	router := validatingRouter(t, config.OpenAPIValidateResponsesKey, &mockService{})
+	for _, path := range []string{"/v1/cart/someCart", "/v1/items/available", "/v1/health", "/v1/health/history"} {
+		if r := serveJSON(router, http.MethodGet, path, ""); r.Result().StatusCode != http.StatusOK {
+			t.Fatalf("%s: unexpected Status Code %d", path, r.Result().StatusCode)
+		}
+	}
+	r := serveJSON(router, http.MethodPost, "/v1/cart", "")
+	if r.Result().StatusCode != http.StatusOK || !strings.Contains(r.Body.String(), `"cart"`) {
+		t.Fatalf("The buffered response was expected to be written")
+	}
+}
+
+func TestResponseNotMatchingSpecPanics(t *testing.T) {
+	//deleting a cart is not documented to answer 422
+	router := validatingRouter(t, config.OpenAPIValidateResponsesKey, &mockService{
+		deleteErr: serviceErrors.ServiceError{Code: serviceErrors.ItemAlreadyInCartCode},
+	})
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("A response not matching the spec was expected to panic")
+		}
+	}()
+	serveJSON(router, http.MethodDelete, "/v1/cart/someCart", "")
+}
@@ -7,10 +7,12 @@ import (
 
 	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
 	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
 )
 
 type Meta struct {
-	Version string `json:"version"`
+	Version   string `json:"version"`
+	RequestID string `json:"request_id,omitempty"`
 }
 
 type BaseResponse struct {
@@ -19,19 +21,21 @@ type BaseResponse struct {
 	Error interface{} `json:"error,omitempty"`
 }
 
-func newBaseResponseWithData(data interface{}) BaseResponse {
+func newBaseResponseWithData(w http.ResponseWriter, data interface{}) BaseResponse {
 	return BaseResponse{
 		Meta: Meta{
-			Version: config.GetVersion(),
+			Version:   config.GetVersion(),
+			RequestID: w.Header().Get(requestid.HeaderKey),
 		},
 		Data: data,
 	}
 }
 
-func newBaseResponseWithError(err interface{}) BaseResponse {
+func newBaseResponseWithError(w http.ResponseWriter, err interface{}) BaseResponse {
 	return BaseResponse{
 		Meta: Meta{
-			Version: config.GetVersion(),
+			Version:   config.GetVersion(),
+			RequestID: w.Header().Get(requestid.HeaderKey),
 		},
 		Error: err,
 	}
@@ -40,65 +44,54 @@ func newBaseResponseWithError(err interface{}) BaseResponse {
 func RespondWithData(w http.ResponseWriter, statusCode int, data interface{}) error {
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
-	return json.NewEncoder(w).Encode(newBaseResponseWithData(data))
+	return json.NewEncoder(w).Encode(newBaseResponseWithData(w, data))
 }
 
-func RespondWithError(w http.ResponseWriter, err error) error {
+//RespondWithError answers with the error in the BaseResponse envelope, or as an RFC 7807 problem
+//when the request prefers application/problem+json. The description is in the locale the
+//Accept-Language header prefers.
+func RespondWithError(w http.ResponseWriter, r *http.Request, err error) error {
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Accept-Language")
+	status := statusCodeFromError(err)
+	locale := preferredLocale(r)
+	w.Header().Set("Content-Language", locale)
+	vErr := localize(viewModelFromError(err), locale)
+	if prefersProblem(r) {
+		w.Header().Set("Content-Type", ProblemContentType)
+		w.WriteHeader(status)
+		return json.NewEncoder(w).Encode(newProblem(w, r, status, vErr))
+	}
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCodeFromError(err))
-	return json.NewEncoder(w).Encode(newBaseResponseWithError(viewModelFromError(err)))
+	w.WriteHeader(status)
+	return json.NewEncoder(w).Encode(newBaseResponseWithError(w, vErr))
 }
 
+//statusCodeFromError is the status the error code is registered with
 func statusCodeFromError(err error) int {
-	mErr := &serviceErrors.ServiceError{}
-	if errors.As(err, mErr) {
-		switch mErr.Code {
-		case serviceErrors.CartNotFoundCode, serviceErrors.ItemNotFoundCode, serviceErrors.ItemNotFoundOnProviderCode:
-			return http.StatusNotFound
-		case serviceErrors.ItemAlreadyInCartCode:
-			return http.StatusUnprocessableEntity
-		default:
-			return http.StatusInternalServerError
-		}
-	}
-	vErr := &Error{}
-	if errors.As(err, vErr) {
-		switch vErr.Code {
-		case ErrCodeBadRequest:
-			return http.StatusBadRequest
-		default:
-			return http.StatusInternalServerError
-		}
-	}
-
-	return http.StatusInternalServerError
-}
-
-func descriptionFromError(mErr *serviceErrors.ServiceError) string {
-
-	switch mErr.Code {
-	case serviceErrors.ItemNotFoundOnProviderCode:
-		return ErrDescriptionItemNotFoundProvider
-	case serviceErrors.CartNotFoundCode:
-		return ErrDescriptionCartNotFound
-	case serviceErrors.ItemAlreadyInCartCode:
-		return ErrDescriptionItemAlreadyInCart
-	case serviceErrors.ItemNotFoundCode:
-		return ErrDescriptionItemNotFound
-	}
-	return ErrDescriptionInternalServerError
+	return serviceErrors.Lookup(viewModelFromError(err).Code).Status
 }
 
+//viewModelFromError gives what clients see of err, the cause of service errors is left out
 func viewModelFromError(err error) Error {
 	sErr := &serviceErrors.ServiceError{}
 	if errors.As(err, sErr) {
+		definition := serviceErrors.Lookup(sErr.Code)
 		return Error{
-			Code:        err.Error(),
-			Description: descriptionFromError(sErr),
+			Code:        sErr.Code,
+			Description: definition.Description,
+			Retryable:   definition.Retryable,
+			MessageKey:  sErr.Code,
 		}
 	}
 	vErr := Error{}
 	if errors.As(err, &vErr) {
+		definition := serviceErrors.Lookup(vErr.Code)
+		if vErr.Description == "" {
+			vErr.Description = definition.Description
+			vErr.MessageKey = vErr.Code
+		}
+		vErr.Retryable = definition.Retryable
 		return vErr
 	}
 	return StandardInternalServerError
@@ -27,7 +27,7 @@ func TestRespondWithErrNotFound(t *testing.T) {
 	mErr := serviceErrors.ServiceError{
 		Code: serviceErrors.CartNotFoundCode,
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusNotFound {
 		t.Fatalf("Unexpected Status Code")
@@ -39,7 +39,7 @@ func TestRespondWithErrItemNotFound(t *testing.T) {
 	mErr := serviceErrors.ServiceError{
 		Code: serviceErrors.ItemNotFoundCode,
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusNotFound {
 		t.Fatalf("Unexpected Status Code")
@@ -51,7 +51,7 @@ func TestRespondWithErrItemNotFoundOnProvider(t *testing.T) {
 	mErr := serviceErrors.ServiceError{
 		Code: serviceErrors.ItemNotFoundOnProviderCode,
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusNotFound {
 		t.Fatalf("Unexpected Status Code")
@@ -63,7 +63,7 @@ func TestRespondWithErrUnprocessableEntity(t *testing.T) {
 	mErr := serviceErrors.ServiceError{
 		Code: serviceErrors.ItemAlreadyInCartCode,
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusUnprocessableEntity {
 		t.Fatalf("Unexpected Status Code")
@@ -75,7 +75,7 @@ func TestRespondWithErrInternal(t *testing.T) {
 	mErr := serviceErrors.ServiceError{
 		Code: "some Error Code",
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusInternalServerError {
 		t.Fatalf("Unexpected Status Code")
@@ -84,7 +84,7 @@ func TestRespondWithErrInternal(t *testing.T) {
 
 func TestRespondWithErrInternalDefault(t *testing.T) {
 	r := httptest.NewRecorder()
-	viewmodels.RespondWithError(r, fmt.Errorf("Some error"))
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), fmt.Errorf("Some error"))
 
 	if r.Result().StatusCode != http.StatusInternalServerError {
 		t.Fatalf("Unexpected Status Code")
@@ -96,7 +96,7 @@ func TestRespondWithErrBadReq(t *testing.T) {
 	mErr := viewmodels.Error{
 		Code: viewmodels.ErrCodeBadRequest,
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusBadRequest {
 		t.Fatalf("Unexpected Status Code")
@@ -108,7 +108,7 @@ func TestRespondWithErrViewInternalError(t *testing.T) {
 	mErr := viewmodels.Error{
 		Code: "SomeCode",
 	}
-	viewmodels.RespondWithError(r, mErr)
+	viewmodels.RespondWithError(r, httptest.NewRequest(http.MethodGet, "/", nil), mErr)
 
 	if r.Result().StatusCode != http.StatusInternalServerError {
 		t.Fatalf("Unexpected Status Code")
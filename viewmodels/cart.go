@@ -43,6 +43,25 @@ type AddItemToCartRequest struct {
 	Quantity int    `json:"quantity"`
 }
 
+func (r AddItemToCartRequest) Validate() []Violation {
+	violations := []Violation{}
+	if r.ID == "" {
+		violations = append(violations, Violation{Pointer: Pointer("id"), Rule: RuleRequired, Message: "is required"})
+	}
+	if r.Quantity < 1 {
+		violations = append(violations, Violation{Pointer: Pointer("quantity"), Rule: RuleMin, Message: "must be at least 1"})
+	}
+	return violations
+}
+
 type ModifyItemQuantityRequest struct {
 	Quantity int `json:"quantity"`
 }
+
+func (r ModifyItemQuantityRequest) Validate() []Violation {
+	violations := []Violation{}
+	if r.Quantity < 1 {
+		violations = append(violations, Violation{Pointer: Pointer("quantity"), Rule: RuleMin, Message: "must be at least 1"})
+	}
+	return violations
+}
@@ -0,0 +1,35 @@
+package viewmodels
+
+const (
+	CollabCommandAdd    = "add"
+	CollabCommandUpdate = "update"
+	CollabCommandRemove = "remove"
+
+	CollabMessageCart     = "cart"
+	CollabMessagePresence = "presence"
+	CollabMessageError    = "error"
+)
+
+//CollabCommand is what a client sends over the shared cart WebSocket
+type CollabCommand struct {
+	Type     string `json:"type"`
+	ItemID   string `json:"item_id"`
+	Quantity int    `json:"quantity,omitempty"`
+}
+
+//CollabMessage is what the server pushes over the shared cart WebSocket
+type CollabMessage struct {
+	Type    string   `json:"type"`
+	Cart    *Cart    `json:"cart,omitempty"`
+	Viewers []string `json:"viewers,omitempty"`
+	Error   *Error   `json:"error,omitempty"`
+}
+
+//CollabErrorMessage builds the error pushed to a single client when its command fails
+func CollabErrorMessage(err error) CollabMessage {
+	vErr := viewModelFromError(err)
+	return CollabMessage{
+		Type:  CollabMessageError,
+		Error: &vErr,
+	}
+}
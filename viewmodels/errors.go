@@ -1,31 +1,45 @@
 package viewmodels
 
-import "fmt"
+import (
+	"fmt"
 
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+)
+
+//Codes of the errors raised by the transport, their status and description are in the registry of pkg/errors
 const (
-	ErrCodeInternalServerError        = "err_internal"
-	ErrDescriptionInternalServerError = "Internal Server Error"
+	ErrCodeInternalServerError = serviceErrors.InternalCode
+	ErrCodeBadRequest          = serviceErrors.BadRequestCode
 
-	ErrCodeBadRequest            = "err_bad_request"
 	ErrDescriptionBadRequestURL  = "The URL In Request contains errors"
 	ErrDescriptionBadRequestBody = "The provided body contains errors"
+	ErrDescriptionBadTenant      = "The tenant is not valid or not allowed"
+	ErrDescriptionBadRequestSpec = "The request does not match the API specification"
+)
 
-	ErrDescriptionCartNotFound = "The Cart ID was not found"
-
-	ErrDescriptionItemAlreadyInCart = "The item already exists in the cart"
-	ErrDescriptionItemNotFound      = "The item does not exists in the cart"
-
-	ErrDescriptionItemNotFoundProvider = "The item was not found on the provider"
+//Keys of the catalog messages of the descriptions above
+const (
+	MessageKeyBadRequestURL  = ErrCodeBadRequest + ".url"
+	MessageKeyBadRequestBody = ErrCodeBadRequest + ".body"
+	MessageKeyBadTenant      = ErrCodeBadRequest + ".tenant"
+	MessageKeyBadRequestSpec = ErrCodeBadRequest + ".spec"
 )
 
 var (
-	StandardInternalServerError = Error{ErrCodeInternalServerError, ErrDescriptionInternalServerError}
-	StandardBadBodyRequest      = Error{ErrCodeBadRequest, ErrDescriptionBadRequestBody}
+	StandardInternalServerError = Error{Code: ErrCodeInternalServerError, Description: serviceErrors.Lookup(ErrCodeInternalServerError).Description, MessageKey: ErrCodeInternalServerError}
+	StandardBadBodyRequest      = Error{Code: ErrCodeBadRequest, Description: ErrDescriptionBadRequestBody, MessageKey: MessageKeyBadRequestBody}
+	StandardBadTenantRequest    = Error{Code: ErrCodeBadRequest, Description: ErrDescriptionBadTenant, MessageKey: MessageKeyBadTenant}
 )
 
 type Error struct {
 	Code        string `json:"code"`
 	Description string `json:"description"`
+	//Retryable errors may go away when the request is retried
+	Retryable bool `json:"retryable"`
+	//Details are the inputs of the request that broke a rule
+	Details []Violation `json:"details,omitempty"`
+	//MessageKey is the catalog message the description is translated from, descriptions without one are left in English
+	MessageKey string `json:"-"`
 }
 
 func (e Error) Error() string {
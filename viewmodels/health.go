@@ -1,9 +1,30 @@
 package viewmodels
 
+import "time"
+
 type Health struct {
-	Name  string `json:"name"`
-	Alive bool   `json:"alive"`
+	Name          string     `json:"name"`
+	Alive         bool       `json:"alive"`
+	Status        string     `json:"status"`
+	Critical      bool       `json:"critical"`
+	LatencyMs     float64    `json:"latency_ms"`
+	LastError     string     `json:"last_error,omitempty"`
+	LastErrorAt   *time.Time `json:"last_error_at,omitempty"`
+	LastSuccessAt *time.Time `json:"last_success_at,omitempty"`
 }
 type HealthResponse struct {
+	Status   string   `json:"status"`
 	Services []Health `json:"services"`
 }
+
+type HealthTransition struct {
+	Name       string    `json:"name"`
+	From       string    `json:"from,omitempty"`
+	To         string    `json:"to"`
+	At         time.Time `json:"at"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+type HealthHistoryResponse struct {
+	Transitions []HealthTransition `json:"transitions"`
+}
@@ -0,0 +1,94 @@
+package viewmodels
+
+import (
+	"embed"
+	"encoding/json"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//DefaultLocale is the locale of clients asking for none we ship
+const DefaultLocale = "en"
+
+//locales holds a message catalog per locale, named after it
+//
+//go:embed locales/*.json
+var locales embed.FS
+
+//catalogs are the messages of every locale, by locale and then by error code.
+//Descriptions the transport sets on an error code are under the code and a suffix, like err_bad_request.body
+var catalogs = map[string]map[string]string{}
+
+func init() {
+	files, err := locales.ReadDir("locales")
+	if err != nil {
+		panic(err)
+	}
+	for _, f := range files {
+		raw, err := locales.ReadFile(path.Join("locales", f.Name()))
+		if err != nil {
+			panic(err)
+		}
+		catalog := map[string]string{}
+		if err := json.Unmarshal(raw, &catalog); err != nil {
+			panic("locale " + f.Name() + ": " + err.Error())
+		}
+		catalogs[strings.TrimSuffix(f.Name(), ".json")] = catalog
+	}
+}
+
+//Locales are the locales shipped, sorted
+func Locales() []string {
+	list := make([]string, 0, len(catalogs))
+	for locale := range catalogs {
+		list = append(list, locale)
+	}
+	sort.Strings(list)
+	return list
+}
+
+//Message gives the message of key in locale, keys are error codes
+func Message(locale, key string) (string, bool) {
+	message, ok := catalogs[locale][key]
+	return message, ok
+}
+
+//localize translates the description of vErr to locale by its MessageKey, errors without one are left as they are
+func localize(vErr Error, locale string) Error {
+	if vErr.MessageKey == "" {
+		return vErr
+	}
+	if message, ok := Message(locale, vErr.MessageKey); ok {
+		vErr.Description = message
+	}
+	return vErr
+}
+
+//preferredLocale is the shipped locale the Accept-Language header ranks first, regional variants
+//match their language (es-AR is es). It is the DefaultLocale when none is shipped.
+func preferredLocale(r *http.Request) string {
+	if r == nil {
+		return DefaultLocale
+	}
+	locale, best := DefaultLocale, 0.0
+	for _, accepted := range strings.Split(strings.Join(r.Header.Values("Accept-Language"), ","), ",") {
+		parts := strings.Split(accepted, ";")
+		language := strings.ToLower(strings.TrimSpace(strings.SplitN(parts[0], "-", 2)[0]))
+		q := 1.0
+		for _, param := range parts[1:] {
+			if v := strings.TrimSpace(param); strings.HasPrefix(v, "q=") {
+				var err error
+				if q, err = strconv.ParseFloat(strings.TrimPrefix(v, "q="), 64); err != nil {
+					q = 0
+				}
+			}
+		}
+		if _, ok := catalogs[language]; ok && q > best {
+			locale, best = language, q
+		}
+	}
+	return locale
+}
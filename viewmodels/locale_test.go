@@ -0,0 +1,141 @@
+package viewmodels_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+)
+
+func TestEveryCodeTranslated(t *testing.T) {
+	keys := []string{
+		viewmodels.MessageKeyBadRequestBody,
+		viewmodels.MessageKeyBadRequestSpec,
+		viewmodels.MessageKeyBadTenant,
+		viewmodels.MessageKeyBadRequestURL,
+	}
+	for _, d := range serviceErrors.Definitions() {
+		keys = append(keys, d.Code)
+	}
+	for _, locale := range viewmodels.Locales() {
+		for _, key := range keys {
+			if message, ok := viewmodels.Message(locale, key); !ok || message == "" {
+				t.Fatalf("%s is not translated to %s", key, locale)
+			}
+		}
+	}
+}
+
+func TestEnglishCatalogMatchesRegistry(t *testing.T) {
+	for _, d := range serviceErrors.Definitions() {
+		if message, _ := viewmodels.Message(viewmodels.DefaultLocale, d.Code); message != d.Description {
+			t.Fatalf("Unexpected English message for %s", d.Code)
+		}
+	}
+	for key, description := range map[string]string{
+		viewmodels.MessageKeyBadRequestBody: viewmodels.ErrDescriptionBadRequestBody,
+		viewmodels.MessageKeyBadRequestSpec: viewmodels.ErrDescriptionBadRequestSpec,
+		viewmodels.MessageKeyBadTenant:      viewmodels.ErrDescriptionBadTenant,
+		viewmodels.MessageKeyBadRequestURL:  viewmodels.ErrDescriptionBadRequestURL,
+	} {
+		if message, _ := viewmodels.Message(viewmodels.DefaultLocale, key); message != description {
+			t.Fatalf("Unexpected English message for %s", key)
+		}
+	}
+}
+
+func TestLocalizedDescription(t *testing.T) {
+	for acceptLanguage, locale := range map[string]string{
+		"":                        "en",
+		"es":                      "es",
+		"es-AR":                   "es",
+		"PT-br":                   "pt",
+		"fr":                      "en",
+		"*":                       "en",
+		"fr, pt;q=0.8, es;q=0.9":  "es",
+		"es;q=0, pt;q=0.1":        "pt",
+		"en;q=0.5, pt-BR;q=0.7":   "pt",
+		"es;q=nonsense, pt;q=0.2": "pt",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		for code, err := range map[string]error{
+			serviceErrors.CartNotFoundCode: serviceErrors.ServiceError{Code: serviceErrors.CartNotFoundCode},
+			//not a service error, answered as an internal one
+			serviceErrors.InternalCode: errors.New("unknown error"),
+		} {
+			r := httptest.NewRecorder()
+			viewmodels.RespondWithError(r, req, err)
+
+			if r.Header().Get("Content-Language") != locale {
+				t.Fatalf("Unexpected locale %s for %q", r.Header().Get("Content-Language"), acceptLanguage)
+			}
+			resp := struct {
+				Error viewmodels.Error `json:"error"`
+			}{}
+			json.NewDecoder(r.Body).Decode(&resp)
+			if expected, _ := viewmodels.Message(locale, code); resp.Error.Description != expected {
+				t.Fatalf("Unexpected description %q of %s for %q", resp.Error.Description, code, acceptLanguage)
+			}
+		}
+	}
+}
+
+func TestLocalizedTransportDescription(t *testing.T) {
+	r := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "pt-BR")
+	req.Header.Set("Accept", viewmodels.ProblemContentType)
+	viewmodels.RespondWithError(r, req, viewmodels.StandardBadBodyRequest)
+
+	problem := viewmodels.Problem{}
+	json.NewDecoder(r.Body).Decode(&problem)
+	if expected, _ := viewmodels.Message("pt", viewmodels.MessageKeyBadRequestBody); problem.Detail != expected {
+		t.Fatalf("Unexpected detail %q", problem.Detail)
+	}
+}
+
+func TestLocalizedByMessageKey(t *testing.T) {
+	spanish := func(key string) string {
+		message, _ := viewmodels.Message("es", key)
+		return message
+	}
+	for _, tc := range []struct {
+		name     string
+		err      viewmodels.Error
+		expected string
+	}{
+		{
+			name:     "reworded description",
+			err:      viewmodels.Error{Code: viewmodels.ErrCodeBadRequest, Description: "The body is wrong", MessageKey: viewmodels.MessageKeyBadRequestBody},
+			expected: spanish(viewmodels.MessageKeyBadRequestBody),
+		},
+		{
+			name:     "description of another message without a key",
+			err:      viewmodels.Error{Code: viewmodels.ErrCodeBadRequest, Description: viewmodels.ErrDescriptionBadTenant},
+			expected: viewmodels.ErrDescriptionBadTenant,
+		},
+		{
+			name:     "description missing",
+			err:      viewmodels.Error{Code: serviceErrors.CartNotFoundCode},
+			expected: spanish(serviceErrors.CartNotFoundCode),
+		},
+	} {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Language", "es")
+		viewmodels.RespondWithError(r, req, tc.err)
+
+		resp := struct {
+			Error viewmodels.Error `json:"error"`
+		}{}
+		json.NewDecoder(r.Body).Decode(&resp)
+		if resp.Error.Description != tc.expected {
+			t.Fatalf("Unexpected description %q for %s", resp.Error.Description, tc.name)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package viewmodels
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/config"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+)
+
+//ProblemContentType is the media type of RFC 7807 problems
+const ProblemContentType = "application/problem+json"
+
+//Problem is an error as described by RFC 7807, Code, RequestID, Version, Retryable and Details are extension members
+type Problem struct {
+	//Type identifies the error code, it is the code under PROBLEM_TYPE_BASE_URL
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+
+	Code      string      `json:"code"`
+	RequestID string      `json:"request_id,omitempty"`
+	Version   string      `json:"version"`
+	Retryable bool        `json:"retryable"`
+	Details   []Violation `json:"details,omitempty"`
+}
+
+func newProblem(w http.ResponseWriter, r *http.Request, status int, vErr Error) Problem {
+	return Problem{
+		Type:      config.GetProblemTypeBaseURL() + vErr.Code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    vErr.Description,
+		Instance:  r.URL.RequestURI(),
+		Code:      vErr.Code,
+		RequestID: w.Header().Get(requestid.HeaderKey),
+		Version:   config.GetVersion(),
+		Retryable: vErr.Retryable,
+		Details:   vErr.Details,
+	}
+}
+
+//prefersProblem tells whether the Accept header ranks application/problem+json above application/json.
+//Wildcards don't count, so clients that don't name it keep getting the BaseResponse envelope.
+func prefersProblem(r *http.Request) bool {
+	if r == nil {
+		return false
+	}
+	problem, plain := 0.0, -1.0
+	for _, accepted := range strings.Split(strings.Join(r.Header.Values("Accept"), ","), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err != nil {
+			continue
+		}
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+		switch mediaType {
+		case ProblemContentType:
+			problem = q
+		case "application/json":
+			plain = q
+		}
+	}
+	return problem > 0 && problem >= plain
+}
@@ -0,0 +1,70 @@
+package viewmodels_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	serviceErrors "github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/errors"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/pkg/requestid"
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+)
+
+func TestRespondWithProblem(t *testing.T) {
+	r := httptest.NewRecorder()
+	r.Header().Set(requestid.HeaderKey, "some-request")
+	req := httptest.NewRequest(http.MethodGet, "/v1/cart/someCart?x=1", nil)
+	req.Header.Set("Accept", viewmodels.ProblemContentType)
+	viewmodels.RespondWithError(r, req, serviceErrors.ServiceError{Code: serviceErrors.CartNotFoundCode})
+
+	if r.Result().StatusCode != http.StatusNotFound {
+		t.Fatalf("Unexpected Status Code")
+	}
+	if r.Header().Get("Content-Type") != viewmodels.ProblemContentType || r.Header().Get("Vary") != "Accept" {
+		t.Fatalf("Unexpected headers %v", r.Header())
+	}
+	problem := viewmodels.Problem{}
+	json.NewDecoder(r.Body).Decode(&problem)
+	expected := viewmodels.Problem{
+		Type:      "/problems/err_cart_not_found",
+		Title:     "Not Found",
+		Status:    http.StatusNotFound,
+		Detail:    serviceErrors.Lookup(serviceErrors.CartNotFoundCode).Description,
+		Instance:  "/v1/cart/someCart?x=1",
+		Code:      serviceErrors.CartNotFoundCode,
+		RequestID: "some-request",
+		Version:   problem.Version,
+	}
+	if !reflect.DeepEqual(problem, expected) {
+		t.Fatalf("Unexpected problem %+v", problem)
+	}
+}
+
+func TestErrorFormatNegotiation(t *testing.T) {
+	for accept, problem := range map[string]bool{
+		"":                         false,
+		"*/*":                      false,
+		"application/json":         false,
+		"application/*":            false,
+		"application/problem+json": true,
+		"application/json, application/problem+json":             true,
+		"application/json, application/problem+json;q=0.5":       false,
+		"application/problem+json;q=0.9, application/json;q=0.8": true,
+		"application/problem+json;q=0":                           false,
+		"text/html, application/problem+json;q=0.1":              true,
+	} {
+		r := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept", accept)
+		viewmodels.RespondWithError(r, req, viewmodels.StandardBadBodyRequest)
+
+		if got := r.Header().Get("Content-Type") == viewmodels.ProblemContentType; got != problem {
+			t.Fatalf("Accept %q: unexpected format %s", accept, r.Header().Get("Content-Type"))
+		}
+		if r.Result().StatusCode != http.StatusBadRequest {
+			t.Fatalf("Unexpected Status Code")
+		}
+	}
+}
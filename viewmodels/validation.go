@@ -0,0 +1,131 @@
+package viewmodels
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+//Rules a request can break
+const (
+	RuleSyntax   = "syntax"
+	RuleType     = "type"
+	RuleUnknown  = "unknown"
+	RuleRequired = "required"
+	RuleMin      = "min"
+	//RuleSchema is any other rule of the OpenAPI spec
+	RuleSchema = "schema"
+)
+
+//Violation is an input of a request breaking a rule
+type Violation struct {
+	//Pointer is the JSON pointer (RFC 6901) of the input, empty for the whole body
+	Pointer string `json:"pointer"`
+	//Parameter is the header, path or query parameter breaking the rule, the pointer is empty then
+	Parameter string `json:"parameter,omitempty"`
+	Rule      string `json:"rule"`
+	Message   string `json:"message"`
+}
+
+//Validatable requests check their rules once decoded
+type Validatable interface {
+	Validate() []Violation
+}
+
+//DecodeRequest decodes body into vm and validates it.
+//Every violation is collected, the error is a bad request Error carrying them as details.
+func DecodeRequest(body io.Reader, vm Validatable) error {
+	violations, ok := decodeFields(body, vm)
+	if ok {
+		broken := map[string]bool{}
+		for _, v := range violations {
+			broken[v.Pointer] = true
+		}
+		//an input that could not be decoded is not checked again
+		for _, v := range vm.Validate() {
+			if !broken[v.Pointer] {
+				violations = append(violations, v)
+			}
+		}
+	}
+	if len(violations) == 0 {
+		return nil
+	}
+	return Error{
+		Code:        ErrCodeBadRequest,
+		Description: ErrDescriptionBadRequestBody,
+		Details:     violations,
+		MessageKey:  MessageKeyBadRequestBody,
+	}
+}
+
+//decodeFields decodes every member of the body on its own, so a bad member does not hide the others.
+//It is not ok when the body itself can't be decoded.
+func decodeFields(body io.Reader, vm interface{}) ([]Violation, bool) {
+	members := map[string]json.RawMessage{}
+	if err := json.NewDecoder(body).Decode(&members); err != nil {
+		typeErr := &json.UnmarshalTypeError{}
+		if errors.As(err, &typeErr) {
+			return []Violation{{Pointer: "", Rule: RuleType, Message: "must be an object"}}, false
+		}
+		return []Violation{{Pointer: "", Rule: RuleSyntax, Message: "is not valid JSON"}}, false
+	}
+
+	fields := map[string]reflect.Value{}
+	v := reflect.ValueOf(vm).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		name := strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = v.Field(i)
+		}
+	}
+
+	violations := []Violation{}
+	names := make([]string, 0, len(members))
+	for name := range members {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pointer := Pointer(name)
+		field, ok := fields[name]
+		if !ok {
+			violations = append(violations, Violation{Pointer: pointer, Rule: RuleUnknown, Message: "is not a known field"})
+			continue
+		}
+		if err := json.Unmarshal(members[name], field.Addr().Interface()); err != nil {
+			violations = append(violations, Violation{Pointer: pointer, Rule: RuleType, Message: fmt.Sprintf("must be %s", kindName(field.Kind()))})
+		}
+	}
+	return violations, true
+}
+
+//Pointer is the JSON pointer of a member of the body
+func Pointer(tokens ...string) string {
+	escaper := strings.NewReplacer("~", "~0", "/", "~1")
+	pointer := ""
+	for _, token := range tokens {
+		pointer += "/" + escaper.Replace(token)
+	}
+	return pointer
+}
+
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "an integer"
+	case reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.Slice:
+		return "an array"
+	}
+	return "an object"
+}
@@ -0,0 +1,63 @@
+package viewmodels_test
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/eduardohoraciosanto/bootcamp-with-gorilla/viewmodels"
+)
+
+func TestDecodeRequestOk(t *testing.T) {
+	vm := viewmodels.AddItemToCartRequest{}
+	if err := viewmodels.DecodeRequest(strings.NewReader(`{"id":"1","quantity":2}`), &vm); err != nil {
+		t.Fatalf("Error was not expected: %v", err)
+	}
+	if vm.ID != "1" || vm.Quantity != 2 {
+		t.Fatalf("Unexpected request %+v", vm)
+	}
+}
+
+func TestDecodeRequestViolations(t *testing.T) {
+	for body, expected := range map[string][]viewmodels.Violation{
+		`{"id":1,"quantity":"two","colour":"red"}`: {
+			{Pointer: "/colour", Rule: viewmodels.RuleUnknown, Message: "is not a known field"},
+			{Pointer: "/id", Rule: viewmodels.RuleType, Message: "must be a string"},
+			{Pointer: "/quantity", Rule: viewmodels.RuleType, Message: "must be an integer"},
+		},
+		`{}`: {
+			{Pointer: "/id", Rule: viewmodels.RuleRequired, Message: "is required"},
+			{Pointer: "/quantity", Rule: viewmodels.RuleMin, Message: "must be at least 1"},
+		},
+		`{"quantity":"two"}`: {
+			{Pointer: "/quantity", Rule: viewmodels.RuleType, Message: "must be an integer"},
+			{Pointer: "/id", Rule: viewmodels.RuleRequired, Message: "is required"},
+		},
+		`{"id":"1","quantity":0,"a/b":1}`: {
+			{Pointer: "/a~1b", Rule: viewmodels.RuleUnknown, Message: "is not a known field"},
+			{Pointer: "/quantity", Rule: viewmodels.RuleMin, Message: "must be at least 1"},
+		},
+		`badBody`: {
+			{Pointer: "", Rule: viewmodels.RuleSyntax, Message: "is not valid JSON"},
+		},
+		`[]`: {
+			{Pointer: "", Rule: viewmodels.RuleType, Message: "must be an object"},
+		},
+	} {
+		err := viewmodels.DecodeRequest(strings.NewReader(body), &viewmodels.AddItemToCartRequest{})
+		vErr := viewmodels.Error{}
+		if !errors.As(err, &vErr) || vErr.Code != viewmodels.ErrCodeBadRequest {
+			t.Fatalf("%s: a bad request was expected, got %v", body, err)
+		}
+		if !reflect.DeepEqual(vErr.Details, expected) {
+			t.Fatalf("%s: unexpected violations %+v", body, vErr.Details)
+		}
+	}
+}
+
+func TestModifyItemQuantityValidation(t *testing.T) {
+	if err := viewmodels.DecodeRequest(strings.NewReader(`{"quantity":-1}`), &viewmodels.ModifyItemQuantityRequest{}); err == nil {
+		t.Fatalf("Error was expected for a negative quantity")
+	}
+}